@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"go.szostok.io/codeowners-validator/internal/config"
+	"go.szostok.io/codeowners-validator/internal/load"
+)
+
+// NewChecks returns a cobra.Command for describing available checks.
+func NewChecks() *cobra.Command {
+	checksCmd := &cobra.Command{
+		Use:   "checks",
+		Short: "Describes available checks.",
+	}
+
+	checksCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "Lists all available checks together with their configuration options.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return printChecks(cmd.OutOrStdout(), load.Registry(), config.NewEnvSource())
+		},
+	})
+
+	return checksCmd
+}
+
+func printChecks(out io.Writer, defs []load.Definition, env *config.EnvSource) error {
+	for idx, def := range defs {
+		if idx > 0 {
+			fmt.Fprintln(out)
+		}
+
+		enabled := "disabled"
+		if def.DefaultEnabled() {
+			enabled = "enabled"
+		}
+
+		fmt.Fprintf(out, "%s - %s\n", def.ID, def.Name)
+		fmt.Fprintf(out, "  %s\n", def.Description)
+		fmt.Fprintf(out, "  Stability: %s (%s by default)\n", def.Stability, enabled)
+		fmt.Fprintf(out, "  Requires:  %s\n", describeDependencies(def.Dependencies))
+
+		if def.Config == nil {
+			fmt.Fprintln(out, "  Options:   none")
+			continue
+		}
+
+		fmt.Fprintln(out, "  Options:")
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "    ENV\tFLAG\tCONFIG FILE KEY\tDEFAULT\tDESCRIPTION")
+		for _, f := range config.Fields(def.Config) {
+			defVal := f.Default
+			if defVal == "" && !f.Optional {
+				defVal = "<required>"
+			}
+			fmt.Fprintf(w, "    %s\t--%s\t%s\t%s\t%s\n", env.EnvName(f), f.FlagName(), f.FileKey(), defVal, f.Description)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func describeDependencies(deps []load.Dependency) string {
+	if len(deps) == 0 {
+		return "nothing, works offline"
+	}
+
+	var out []string
+	for _, d := range deps {
+		switch d {
+		case load.GitHubAPI:
+			out = append(out, "network access and GitHub authorization")
+		case load.GitBinary:
+			out = append(out, "git binary")
+		default:
+			out = append(out, string(d))
+		}
+	}
+	return strings.Join(out, ", ")
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.szostok.io/codeowners-validator/internal/check"
+	"go.szostok.io/codeowners-validator/internal/config"
+	"go.szostok.io/codeowners-validator/internal/load"
+)
+
+func TestPrintChecks(t *testing.T) {
+	// given
+	type fooConfig struct {
+		FooChecker struct {
+			Repository string `desc:"The repository name."`
+			Limit      int    `envconfig:"default=10" desc:"The limit."`
+		}
+	}
+	t.Setenv("ENVS_PREFIX", "INPUT")
+	newChecker := func(context.Context, *config.Loader) (check.Checker, error) { return nil, nil }
+	defs := []load.Definition{
+		{
+			ID:           "foo",
+			Name:         "Foo Checker",
+			Description:  "Reports foo.",
+			Stability:    load.Stable,
+			Config:       &fooConfig{},
+			Dependencies: []load.Dependency{load.GitHubAPI, load.GitBinary},
+			New:          newChecker,
+		},
+		{
+			ID:          "bar",
+			Name:        "Bar Checker",
+			Description: "Reports bar.",
+			Stability:   load.Experimental,
+			New:         newChecker,
+		},
+	}
+	buff := &bytes.Buffer{}
+
+	// when
+	err := printChecks(buff, defs, config.NewEnvSource())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, `foo - Foo Checker
+  Reports foo.
+  Stability: stable (enabled by default)
+  Requires:  network access and GitHub authorization, git binary
+  Options:
+    ENV                           FLAG                      CONFIG FILE KEY        DEFAULT     DESCRIPTION
+    INPUT_FOO_CHECKER_REPOSITORY  --foo-checker-repository  fooChecker.repository  <required>  The repository name.
+    INPUT_FOO_CHECKER_LIMIT       --foo-checker-limit       fooChecker.limit       10          The limit.
+
+bar - Bar Checker
+  Reports bar.
+  Stability: experimental (disabled by default)
+  Requires:  nothing, works offline
+  Options:   none
+`, buff.String())
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"go.szostok.io/codeowners-validator/internal/config"
+	"go.szostok.io/codeowners-validator/internal/load"
+)
+
+const redacted = "<redacted>"
+
+// configSection groups configuration options printed together.
+type configSection struct {
+	Name   string
+	Values []config.Value
+}
+
+// NewConfigCmd returns a cobra.Command for inspecting the application configuration.
+func NewConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspects the application configuration.",
+	}
+
+	printCmd := &cobra.Command{
+		Use:   "print [REPOSITORY_PATH]",
+		Short: "Prints the fully resolved configuration together with the source of each value.",
+		Long: "Prints the fully resolved configuration together with the source of each value.\n\n" +
+			"Accepts the same flags, environment variables and configuration file as the root command.\n" +
+			"Options of the checks that are not selected are omitted. Secrets are redacted.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := setRepositoryPathArg(cmd.Flags(), args); err != nil {
+				return err
+			}
+
+			cfgLoader, cfg, err := loadConfig(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			registry, err := load.Extend(load.Plugins(cfg.Plugins)...)
+			if err != nil {
+				return err
+			}
+
+			defs, err := load.SelectFrom(registry, cfg.selection())
+			if err != nil {
+				return err
+			}
+
+			sections := []configSection{{Name: "general", Values: cfgLoader.Describe(&Config{})}}
+			for _, def := range defs {
+				if def.Config == nil {
+					continue
+				}
+				sections = append(sections, configSection{Name: def.ID, Values: cfgLoader.Describe(def.Config)})
+			}
+
+			if err := printConfig(cmd.OutOrStdout(), sections, config.NewEnvSource()); err != nil {
+				return err
+			}
+
+			// report malformed or missing options the same way as they are reported when checks are loaded
+			for _, def := range defs {
+				if def.Config == nil {
+					continue
+				}
+				if err := cfgLoader.Load(def.Config); err != nil {
+					return errors.Wrapf(err, "while loading config for %s", def.ID)
+				}
+			}
+			return nil
+		},
+	}
+	registerConfigFlags(printCmd)
+
+	configCmd.AddCommand(printCmd)
+
+	return configCmd
+}
+
+func printConfig(out io.Writer, sections []configSection, env *config.EnvSource) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SECTION\tENV\tVALUE\tSOURCE")
+	for _, s := range sections {
+		for _, v := range s.Values {
+			val, src := v.Raw, v.Source
+			switch {
+			case src == "":
+				val, src = "", "not set"
+			case v.Field.Secret && val != "":
+				val = redacted
+			case src == config.DefaultSourceName && v.Field.IsSlice():
+				val = strings.ReplaceAll(val, ";", ",")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, env.EnvName(v.Field), val, src)
+		}
+	}
+	return w.Flush()
+}
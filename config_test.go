@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.szostok.io/codeowners-validator/internal/config"
+)
+
+func TestPrintConfig(t *testing.T) {
+	// given
+	type testConfig struct {
+		RepositoryPath string
+		AccessToken    string   `envconfig:"optional" secret:"true"`
+		AppPrivateKey  string   `envconfig:"optional" secret:"true"`
+		Owners         []string `envconfig:"default=@ghost;@octocat"`
+		Checks         []string `envconfig:"optional"`
+		Timeout        string   `envconfig:"default=30s"`
+	}
+	t.Setenv("ACCESS_TOKEN", "s3cr3t-token")
+
+	repo := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "policy"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "policy", "org.yaml"), []byte("timeout: 1m\nrepositoryPath: ./base"), 0o600))
+	path := filepath.Join(repo, config.FileNames[0])
+	require.NoError(t, os.WriteFile(path, []byte("extends: policy/org.yaml\nrepositoryPath: ./repo"), 0o600))
+
+	fileSrc, err := config.NewFileSource(path, repo)
+	require.NoError(t, err)
+	loader := config.NewLoader(config.NewEnvSource(), fileSrc)
+
+	buff := &bytes.Buffer{}
+
+	// when
+	err = printConfig(buff, []configSection{{Name: "general", Values: loader.Describe(&testConfig{})}}, config.NewEnvSource())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, `SECTION  ENV              VALUE            SOURCE
+general  REPOSITORY_PATH  ./repo           file (`+path+`)
+general  ACCESS_TOKEN     <redacted>       env
+general  APP_PRIVATE_KEY                   not set
+general  OWNERS           @ghost,@octocat  default
+general  CHECKS                            not set
+general  TIMEOUT          1m               file (`+filepath.Join("policy", "org.yaml")+`)
+`, buff.String())
+	assert.NotContains(t, buff.String(), "s3cr3t-token")
+}
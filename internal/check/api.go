@@ -3,6 +3,7 @@ package check
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"strings"
 	"sync"
 
@@ -24,7 +25,9 @@ type (
 	}
 
 	Input struct {
-		RepoDir           string
+		RepoDir string
+		// RepoFS gives access to the repository files. If nil, files are read from RepoDir.
+		RepoFS            fs.FS
 		CodeownersEntries []codeowners.Entry
 	}
 
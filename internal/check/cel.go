@@ -0,0 +1,248 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.szostok.io/codeowners-validator/internal/ctxutil"
+	"go.szostok.io/codeowners-validator/pkg/codeowners"
+
+	"github.com/google/cel-go/cel"
+	"github.com/pkg/errors"
+)
+
+const (
+	// CELEntryScope rules are evaluated for each CODEOWNERS entry.
+	CELEntryScope = "entry"
+	// CELFileScope rules are evaluated for each repository file.
+	CELFileScope = "file"
+)
+
+// CELConfig holds custom rules written as CEL expressions.
+type CELConfig struct {
+	Rules []CELRule `envconfig:"optional" desc:"The JSON list of CEL rules, e.g. [{\"name\": \"teams-only\", \"scope\": \"entry\", \"expression\": \"entry.owners.all(o, o.contains('/'))\", \"message\": \"Only teams are allowed\"}]."`
+	// Workspace is shared with other checks, so it's set by the caller instead of being loaded under this check prefix.
+	Workspace WorkspaceConfig `envconfig:"-"`
+}
+
+// CELRule describes a custom rule. The expression must evaluate to true,
+// otherwise an issue with the given message is reported.
+type CELRule struct {
+	Name string `json:"name"`
+	// Scope is either entry or file. Defaults to entry.
+	Scope string `json:"scope"`
+	// Expression has access to the `entry` variable with the pattern, owners, line and section fields,
+	// or to the `file` variable with the path, owners and entry fields. The file entry is null for unowned files.
+	Expression string `json:"expression"`
+	Message    string `json:"message"`
+	// Severity is either error or warning. Defaults to error.
+	Severity string `json:"severity"`
+}
+
+// celRule is a compiled CELRule.
+type celRule struct {
+	CELRule
+	severity SeverityType
+	program  cel.Program
+}
+
+// CEL reports CODEOWNERS entries and repository files which break custom rules written as CEL expressions.
+// see: https://github.com/google/cel-spec
+type CEL struct {
+	entryRules []celRule
+	fileRules  []celRule
+	workspace  WorkspaceConfig
+}
+
+// NewCEL returns a new CEL instance. Returns an error if any of the rules cannot be compiled.
+func NewCEL(cfg CELConfig) (*CEL, error) {
+	env, err := cel.NewEnv(
+		cel.Variable(CELEntryScope, cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable(CELFileScope, cel.MapType(cel.StringType, cel.DynType)),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "while creating CEL environment")
+	}
+
+	out := &CEL{workspace: cfg.Workspace}
+	for idx, r := range cfg.Rules {
+		rule, err := compileCELRule(env, r)
+		if err != nil {
+			name := r.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", idx+1)
+			}
+			return nil, errors.Wrapf(err, "while compiling CEL rule %s", name)
+		}
+
+		if rule.Scope == CELFileScope {
+			out.fileRules = append(out.fileRules, rule)
+		} else {
+			out.entryRules = append(out.entryRules, rule)
+		}
+	}
+	return out, nil
+}
+
+func compileCELRule(env *cel.Env, r CELRule) (celRule, error) {
+	switch {
+	case r.Name == "":
+		return celRule{}, errors.New("name cannot be empty")
+	case r.Message == "":
+		return celRule{}, errors.New("message cannot be empty")
+	}
+
+	if r.Scope == "" {
+		r.Scope = CELEntryScope
+	}
+	if r.Scope != CELEntryScope && r.Scope != CELFileScope {
+		return celRule{}, fmt.Errorf("not supported scope %q, allowed values: %s, %s", r.Scope, CELEntryScope, CELFileScope)
+	}
+
+	severity := Error
+	if r.Severity != "" {
+		if err := severity.Unmarshal(r.Severity); err != nil {
+			return celRule{}, err
+		}
+	}
+
+	ast, issues := env.Compile(r.Expression)
+	if issues != nil && issues.Err() != nil {
+		return celRule{}, issues.Err()
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return celRule{}, fmt.Errorf("expression must evaluate to bool, got %s", ast.OutputType())
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return celRule{}, err
+	}
+
+	return celRule{CELRule: r, severity: severity, program: program}, nil
+}
+
+func (c *CEL) Check(ctx context.Context, in Input) (Output, error) {
+	if ctxutil.ShouldExit(ctx) {
+		return Output{}, ctx.Err()
+	}
+
+	var bldr OutputBuilder
+	for _, rule := range c.entryRules {
+		for _, entry := range in.CodeownersEntries {
+			if ctxutil.ShouldExit(ctx) {
+				return Output{}, ctx.Err()
+			}
+
+			ok, err := rule.eval(map[string]interface{}{CELEntryScope: celEntry(&entry), CELFileScope: nil})
+			if err != nil {
+				return Output{}, errors.Wrapf(err, "while evaluating CEL rule %q for line %d", rule.Name, entry.LineNo)
+			}
+			if !ok {
+				bldr.ReportIssue(fmt.Sprintf("Rule %q: %s", rule.Name, rule.Message), WithEntry(entry), WithSeverity(rule.severity))
+			}
+		}
+	}
+
+	if len(c.fileRules) == 0 {
+		return bldr.Output(), nil
+	}
+
+	files, err := resolveOwnership(ctx, in, c.workspace)
+	if err != nil {
+		return Output{}, errors.Wrap(err, "while resolving files ownership")
+	}
+
+	for _, rule := range c.fileRules {
+		violations, err := c.evalFiles(rule, files)
+		if err != nil {
+			return Output{}, err
+		}
+
+		for _, v := range violations {
+			msg := fmt.Sprintf("Rule %q: %s: %s", rule.Name, describeFiles(v.files, v.entry == nil), rule.Message)
+			opts := []ReportIssueOpt{WithSeverity(rule.severity)}
+			if v.entry != nil {
+				opts = append(opts, WithEntry(*v.entry))
+			}
+			bldr.ReportIssue(msg, opts...)
+		}
+	}
+
+	return bldr.Output(), nil
+}
+
+// evalFiles returns files which break a given rule grouped by the effective CODEOWNERS entry.
+func (*CEL) evalFiles(rule celRule, files []fileOwnership) ([]policyViolation, error) {
+	grouped := map[uint64]*policyViolation{}
+	for _, own := range files {
+		ok, err := rule.eval(map[string]interface{}{
+			CELEntryScope: nil,
+			CELFileScope: map[string]interface{}{
+				"path":   own.Path,
+				"owners": ownersOrEmpty(own.Owners()),
+				"entry":  celEntry(own.Entry),
+			},
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "while evaluating CEL rule %q for file %q", rule.Name, own.Path)
+		}
+		if ok {
+			continue
+		}
+
+		key := lineNo(own.Entry)
+		if _, found := grouped[key]; !found {
+			grouped[key] = &policyViolation{entry: own.Entry}
+		}
+		grouped[key].files = append(grouped[key].files, own.Path)
+	}
+
+	out := make([]policyViolation, 0, len(grouped))
+	for _, v := range grouped {
+		sort.Strings(v.files)
+		out = append(out, *v)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return lineNo(out[i].entry) < lineNo(out[j].entry)
+	})
+	return out, nil
+}
+
+func (r celRule) eval(vars map[string]interface{}) (bool, error) {
+	val, _, err := r.program.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+
+	ok, isBool := val.Value().(bool)
+	if !isBool {
+		return false, fmt.Errorf("expression must evaluate to bool, got %v", val.Type())
+	}
+	return ok, nil
+}
+
+// celEntry returns the CEL representation of a given entry, nil if the entry is not set.
+func celEntry(e *codeowners.Entry) interface{} {
+	if e == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"pattern": e.Pattern,
+		"owners":  ownersOrEmpty(e.Owners),
+		"line":    int64(e.LineNo),
+		"section": e.Section,
+	}
+}
+
+func ownersOrEmpty(owners []string) []string {
+	if owners == nil {
+		return []string{}
+	}
+	return owners
+}
+
+func (*CEL) Name() string {
+	return "[Experimental] CEL Rules Checker"
+}
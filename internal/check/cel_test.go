@@ -0,0 +1,183 @@
+package check_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"go.szostok.io/codeowners-validator/internal/check"
+	"go.szostok.io/codeowners-validator/internal/ptr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const celFixtureCODEOWNERS = `
+# Defaults
+*          @org/everyone
+
+# Frontend
+/web/      @org/frontend @alice
+/web/docs/ @org/docs
+
+# Infrastructure
+/infra/    @org/platform-owners
+/infra/tmp/
+`
+
+func TestCELEntryRules(t *testing.T) {
+	// given
+	sut, err := check.NewCEL(check.CELConfig{Rules: []check.CELRule{
+		{
+			Name:       "teams-only",
+			Expression: "entry.owners.all(o, o.contains('/'))",
+			Message:    "only teams are allowed",
+			Severity:   "warning",
+		},
+		{
+			Name:       "frontend-reviews",
+			Scope:      "entry",
+			Expression: "entry.section != 'Frontend' || '@org/frontend' in entry.owners",
+			Message:    "frontend entries must include @org/frontend",
+		},
+		{
+			Name:       "owned",
+			Expression: "size(entry.owners) > 0 || entry.line < 10",
+			Message:    "entries must have owners",
+		},
+	}})
+	require.NoError(t, err)
+
+	// when
+	out, err := sut.Check(context.Background(), LoadInput(celFixtureCODEOWNERS))
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []check.Issue{
+		{Severity: check.Warning, LineNo: ptr.Uint64Ptr(6), Message: `Rule "teams-only": only teams are allowed`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(7), Message: `Rule "frontend-reviews": frontend entries must include @org/frontend`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(11), Message: `Rule "owned": entries must have owners`},
+	}, out.Issues)
+}
+
+func TestCELFileRules(t *testing.T) {
+	// given
+	sut, err := check.NewCEL(check.CELConfig{Rules: []check.CELRule{
+		{
+			Name:       "owned-files",
+			Scope:      "file",
+			Expression: "file.entry != null && size(file.owners) > 0",
+			Message:    "every file must be owned",
+		},
+		{
+			Name:       "infra-owners",
+			Scope:      "file",
+			Expression: "!file.path.startsWith('infra/') || file.owners.exists(o, o.endsWith('-owners'))",
+			Message:    "infrastructure must be owned by an -owners team",
+			Severity:   "warning",
+		},
+	}})
+	require.NoError(t, err)
+
+	in := LoadInput(celFixtureCODEOWNERS)
+	in.RepoFS = fstest.MapFS{
+		"README.md":      &fstest.MapFile{},
+		"web/index.html": &fstest.MapFile{},
+		"infra/main.tf":  &fstest.MapFile{},
+		"infra/tmp/a.tf": &fstest.MapFile{},
+		"infra/tmp/b.tf": &fstest.MapFile{},
+	}
+
+	// when
+	out, err := sut.Check(context.Background(), in)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []check.Issue{
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(11), Message: `Rule "owned-files": "infra/tmp/a.tf" and "infra/tmp/b.tf": every file must be owned`},
+		{Severity: check.Warning, LineNo: ptr.Uint64Ptr(11), Message: `Rule "infra-owners": "infra/tmp/a.tf" and "infra/tmp/b.tf": infrastructure must be owned by an -owners team`},
+	}, out.Issues)
+}
+
+func TestCELUnownedFiles(t *testing.T) {
+	// given
+	sut, err := check.NewCEL(check.CELConfig{Rules: []check.CELRule{
+		{Name: "owned-files", Scope: "file", Expression: "file.entry != null", Message: "every file must be owned"},
+	}})
+	require.NoError(t, err)
+
+	in := LoadInput("/docs/ @org/docs")
+	in.RepoFS = fstest.MapFS{"main.go": &fstest.MapFile{}, "docs/index.md": &fstest.MapFile{}}
+
+	// when
+	out, err := sut.Check(context.Background(), in)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []check.Issue{
+		{Severity: check.Error, Message: `Rule "owned-files": "main.go" not owned by any entry: every file must be owned`},
+	}, out.Issues)
+}
+
+func TestCELEvaluationFailure(t *testing.T) {
+	// given
+	sut, err := check.NewCEL(check.CELConfig{Rules: []check.CELRule{
+		{Name: "missing-field", Expression: "entry.team == 'x'", Message: "msg"},
+	}})
+	require.NoError(t, err)
+
+	// when
+	out, err := sut.Check(context.Background(), LoadInput("* @org/team"))
+
+	// then
+	assert.EqualError(t, err, `while evaluating CEL rule "missing-field" for line 1: no such key: team`)
+	assert.Empty(t, out)
+}
+
+func TestNewCELFailures(t *testing.T) {
+	tests := map[string]struct {
+		rule      check.CELRule
+		expErrMsg string
+	}{
+		"Should require name": {
+			rule:      check.CELRule{Expression: "true", Message: "msg"},
+			expErrMsg: "while compiling CEL rule #1: name cannot be empty",
+		},
+		"Should require message": {
+			rule:      check.CELRule{Name: "rule", Expression: "true"},
+			expErrMsg: "while compiling CEL rule rule: message cannot be empty",
+		},
+		"Should reject unknown scope": {
+			rule:      check.CELRule{Name: "rule", Scope: "repo", Expression: "true", Message: "msg"},
+			expErrMsg: `while compiling CEL rule rule: not supported scope "repo", allowed values: entry, file`,
+		},
+		"Should reject unknown severity": {
+			rule:      check.CELRule{Name: "rule", Expression: "true", Message: "msg", Severity: "fatal"},
+			expErrMsg: `while compiling CEL rule rule: not a valid severity type: "fatal"`,
+		},
+		"Should reject non-boolean expression": {
+			rule:      check.CELRule{Name: "rule", Expression: "'text'", Message: "msg"},
+			expErrMsg: "while compiling CEL rule rule: expression must evaluate to bool, got string",
+		},
+	}
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			// when
+			sut, err := check.NewCEL(check.CELConfig{Rules: []check.CELRule{tc.rule}})
+
+			// then
+			assert.EqualError(t, err, tc.expErrMsg)
+			assert.Nil(t, sut)
+		})
+	}
+
+	t.Run("Should report syntax error", func(t *testing.T) {
+		// when
+		sut, err := check.NewCEL(check.CELConfig{Rules: []check.CELRule{{Name: "rule", Expression: "entry.owners.all(", Message: "msg"}}})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "while compiling CEL rule rule: ERROR: <input>:1:")
+		assert.Nil(t, sut)
+	})
+}
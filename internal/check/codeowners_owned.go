@@ -0,0 +1,82 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.szostok.io/codeowners-validator/internal/ctxutil"
+	"go.szostok.io/codeowners-validator/pkg/codeowners"
+
+	"github.com/pkg/errors"
+)
+
+// catchAllPatterns match all repository files.
+var catchAllPatterns = map[string]struct{}{
+	"*":   {},
+	"/*":  {},
+	"**":  {},
+	"/**": {},
+}
+
+// CodeownersOwnedConfig holds owners required for the CODEOWNERS file itself.
+type CodeownersOwnedConfig struct {
+	RequiredOwners []string `envconfig:"optional" desc:"The comma-separated list of owners that must own the CODEOWNERS file, e.g. @org/admins."`
+}
+
+// CodeownersOwned reports if the CODEOWNERS file itself is not protected by a dedicated entry.
+// Otherwise, anyone who can change files covered by the catch-all entry can also change the review requirements.
+type CodeownersOwned struct {
+	requiredOwners []string
+}
+
+// NewCodeownersOwned returns a new CodeownersOwned instance.
+func NewCodeownersOwned(cfg CodeownersOwnedConfig) *CodeownersOwned {
+	return &CodeownersOwned{requiredOwners: cfg.RequiredOwners}
+}
+
+func (c *CodeownersOwned) Check(ctx context.Context, in Input) (Output, error) {
+	if ctxutil.ShouldExit(ctx) {
+		return Output{}, ctx.Err()
+	}
+
+	codeownersPath, err := codeowners.DetectFileFS(repoFS(in))
+	if err != nil {
+		return Output{}, errors.Wrap(err, "while detecting CODEOWNERS file")
+	}
+
+	var bldr OutputBuilder
+	entry, found := codeowners.NewMatcher(in.CodeownersEntries).Match(codeownersPath)
+	switch {
+	case !found:
+		bldr.ReportIssue(fmt.Sprintf("%q is not owned by anyone, add a dedicated entry, e.g. /%s @org/admins", codeownersPath, codeownersPath))
+		return bldr.Output(), nil
+	case len(entry.Owners) == 0:
+		bldr.ReportIssue(fmt.Sprintf("%q is not owned by anyone, the %q pattern doesn't define owners", codeownersPath, entry.Pattern), WithEntry(entry))
+		return bldr.Output(), nil
+	case isCatchAll(entry.Pattern):
+		bldr.ReportIssue(fmt.Sprintf("%q is owned only by the catch-all %q pattern, add a dedicated entry, e.g. /%s %s", codeownersPath, entry.Pattern, codeownersPath, strings.Join(entry.Owners, " ")), WithEntry(entry))
+	}
+
+	var missing []string
+	for _, o := range c.requiredOwners {
+		if !containsOwner(entry.Owners, o) {
+			missing = append(missing, o)
+		}
+	}
+	if len(missing) > 0 {
+		msg := fmt.Sprintf("%q must be owned by %s, but the %q pattern assigns it to %s", codeownersPath, strings.Join(missing, ", "), entry.Pattern, strings.Join(entry.Owners, ", "))
+		bldr.ReportIssue(msg, WithEntry(entry))
+	}
+
+	return bldr.Output(), nil
+}
+
+func isCatchAll(pattern string) bool {
+	_, found := catchAllPatterns[pattern]
+	return found
+}
+
+func (*CodeownersOwned) Name() string {
+	return "[Experimental] CODEOWNERS Ownership Checker"
+}
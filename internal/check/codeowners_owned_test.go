@@ -0,0 +1,114 @@
+package check_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"go.szostok.io/codeowners-validator/internal/check"
+	"go.szostok.io/codeowners-validator/internal/ptr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeownersOwned(t *testing.T) {
+	tests := map[string]struct {
+		codeowners     string
+		requiredOwners []string
+		expIssues      []check.Issue
+	}{
+		"Should accept dedicated entry": {
+			codeowners: `
+*                   @org/devs
+/.github/CODEOWNERS @org/admins
+`,
+			requiredOwners: []string{"@org/admins"},
+		},
+		"Should report unowned file": {
+			codeowners: `
+/docs/ @org/docs
+`,
+			expIssues: []check.Issue{
+				{
+					Severity: check.Error,
+					Message:  `".github/CODEOWNERS" is not owned by anyone, add a dedicated entry, e.g. /.github/CODEOWNERS @org/admins`,
+				},
+			},
+		},
+		"Should report entry without owners": {
+			codeowners: `
+*                   @org/devs
+/.github/
+`,
+			expIssues: []check.Issue{
+				{
+					Severity: check.Error,
+					LineNo:   ptr.Uint64Ptr(3),
+					Message:  `".github/CODEOWNERS" is not owned by anyone, the "/.github/" pattern doesn't define owners`,
+				},
+			},
+		},
+		"Should report catch-all entry": {
+			codeowners: `
+*      @org/devs
+/docs/ @org/docs
+`,
+			expIssues: []check.Issue{
+				{
+					Severity: check.Error,
+					LineNo:   ptr.Uint64Ptr(2),
+					Message:  `".github/CODEOWNERS" is owned only by the catch-all "*" pattern, add a dedicated entry, e.g. /.github/CODEOWNERS @org/devs`,
+				},
+			},
+		},
+		"Should report missing required owner": {
+			codeowners: `
+*         @org/devs
+/.github/ @org/ci
+`,
+			requiredOwners: []string{"@org/admins"},
+			expIssues: []check.Issue{
+				{
+					Severity: check.Error,
+					LineNo:   ptr.Uint64Ptr(3),
+					Message:  `".github/CODEOWNERS" must be owned by @org/admins, but the "/.github/" pattern assigns it to @org/ci`,
+				},
+			},
+		},
+	}
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			sut := check.NewCodeownersOwned(check.CodeownersOwnedConfig{RequiredOwners: tc.requiredOwners})
+			in := LoadInput(tc.codeowners)
+			in.RepoFS = fstest.MapFS{
+				".github/CODEOWNERS": &fstest.MapFile{},
+				"docs/index.md":      &fstest.MapFile{},
+			}
+
+			// when
+			out, err := sut.Check(context.Background(), in)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, tc.expIssues, out.Issues)
+		})
+	}
+}
+
+func TestCodeownersOwnedNoCodeownersFile(t *testing.T) {
+	// given
+	sut := check.NewCodeownersOwned(check.CodeownersOwnedConfig{})
+	in := LoadInput("* @org/devs")
+	in.RepoFS = fstest.MapFS{
+		"docs/index.md": &fstest.MapFile{},
+	}
+
+	// when
+	out, err := sut.Check(context.Background(), in)
+
+	// then
+	assert.EqualError(t, err, "while detecting CODEOWNERS file: No CODEOWNERS found in the root, docs/, or .github/ directory of the repository")
+	assert.Empty(t, out)
+}
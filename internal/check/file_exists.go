@@ -3,8 +3,10 @@ package check
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
 
 	"go.szostok.io/codeowners-validator/internal/ctxutil"
 
@@ -21,24 +23,26 @@ func NewFileExist() *FileExist {
 func (f *FileExist) Check(ctx context.Context, in Input) (Output, error) {
 	var bldr OutputBuilder
 
+	if ctxutil.ShouldExit(ctx) {
+		return Output{}, ctx.Err()
+	}
+
+	paths, err := f.listPaths(ctx, repoFS(in))
+	if err != nil {
+		return Output{}, errors.Wrapf(err, "while listing files in %s", in.RepoDir)
+	}
+
 	for _, entry := range in.CodeownersEntries {
 		if ctxutil.ShouldExit(ctx) {
 			return Output{}, ctx.Err()
 		}
 
-		fullPath := filepath.Join(in.RepoDir, f.fnmatchPattern(entry.Pattern))
-		matches, err := zglob.Glob(fullPath)
-		switch {
-		case err == nil:
-		case errors.Is(err, os.ErrNotExist):
-			msg := fmt.Sprintf("%q does not match any files in repository", entry.Pattern)
-			bldr.ReportIssue(msg, WithEntry(entry))
-			continue
-		default:
+		found, err := f.matchAny(f.fnmatchPattern(entry.Pattern), paths)
+		if err != nil {
 			return Output{}, errors.Wrapf(err, "while checking if there is any file in %s matching pattern %s", in.RepoDir, entry.Pattern)
 		}
 
-		if len(matches) == 0 {
+		if !found {
 			msg := fmt.Sprintf("%q does not match any files in repository", entry.Pattern)
 			bldr.ReportIssue(msg, WithEntry(entry))
 		}
@@ -47,11 +51,64 @@ func (f *FileExist) Check(ctx context.Context, in Input) (Output, error) {
 	return bldr.Output(), nil
 }
 
+// listPaths returns sorted slash-separated paths of all files and directories in a given repository,
+// except the .git directory.
+func (*FileExist) listPaths(ctx context.Context, fsys fs.FS) ([]string, error) {
+	var paths []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxutil.ShouldExit(ctx) {
+			return ctx.Err()
+		}
+		if d.IsDir() && p == ".git" {
+			return fs.SkipDir
+		}
+		paths = append(paths, p)
+		return nil
+	})
+	sort.Strings(paths)
+	return paths, err
+}
+
+// matchAny returns true if any of sorted paths matches a given pattern. Only paths under the literal
+// directory prefix of the pattern are compared, so most patterns don't scan the whole repository.
+func (*FileExist) matchAny(pattern string, paths []string) (bool, error) {
+	matcher, err := zglob.New(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	prefix := literalDirPrefix(pattern)
+	for i := sort.SearchStrings(paths, prefix); i < len(paths) && strings.HasPrefix(paths[i], prefix); i++ {
+		if matcher.Match(paths[i]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// literalDirPrefix returns the directories of a given pattern which precede the first wildcard, with the trailing slash.
+// Returns the whole pattern if it has no wildcards.
+func literalDirPrefix(pattern string) string {
+	idx := strings.IndexAny(pattern, "*?[{")
+	if idx == -1 {
+		return pattern
+	}
+	return pattern[:strings.LastIndex(pattern[:idx], "/")+1]
+}
+
+// fnmatchPattern returns the pattern relative to the repository root.
 func (*FileExist) fnmatchPattern(pattern string) string {
 	if len(pattern) >= 2 && pattern[:1] == "*" && pattern[1:2] != "*" {
-		return "**/" + pattern
+		pattern = "**/" + pattern
 	}
 
+	pattern = strings.TrimPrefix(path.Clean("/"+pattern), "/")
+	if pattern == "" {
+		return "."
+	}
 	return pattern
 }
 
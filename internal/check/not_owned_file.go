@@ -19,9 +19,9 @@ type NotOwnedFileConfig struct {
 	// TrustWorkspace sets the global gif config
 	// to trust a given repository path
 	// see: https://github.com/actions/checkout/issues/766
-	TrustWorkspace bool     `envconfig:"default=false"`
-	SkipPatterns   []string `envconfig:"optional"`
-	Subdirectories []string `envconfig:"optional"`
+	TrustWorkspace bool     `envconfig:"default=false" sensitive:"true" desc:"Specifies whether the repository path should be marked as safe."`
+	SkipPatterns   []string `envconfig:"optional" desc:"The comma-separated list of patterns that should be ignored."`
+	Subdirectories []string `envconfig:"optional" desc:"The comma-separated list of subdirectories to check."`
 }
 
 type NotOwnedFile struct {
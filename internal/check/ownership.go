@@ -0,0 +1,154 @@
+package check
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"go.szostok.io/codeowners-validator/internal/ctxutil"
+	"go.szostok.io/codeowners-validator/pkg/codeowners"
+
+	"github.com/pkg/errors"
+)
+
+// WorkspaceConfig holds the options of checks which execute git in the repository directory.
+type WorkspaceConfig struct {
+	// TrustWorkspace marks the repository path as safe only for the git commands executed by the check,
+	// so git doesn't fail with the dubious ownership error when the repository belongs to another user.
+	// see: https://github.com/actions/checkout/issues/766
+	TrustWorkspace bool `envconfig:"default=false" sensitive:"true" desc:"Specifies whether the repository path should be marked as safe."`
+}
+
+// fileOwnership holds the effective CODEOWNERS entry of a repository file.
+type fileOwnership struct {
+	Path string
+	// Entry is nil if the file is not owned by any entry.
+	Entry *codeowners.Entry
+}
+
+// Owners returns the effective owners of the file.
+func (o fileOwnership) Owners() []string {
+	if o.Entry == nil {
+		return nil
+	}
+	return o.Entry.Owners
+}
+
+// repoFS returns the repository file system.
+func repoFS(in Input) fs.FS {
+	if in.RepoFS != nil {
+		return in.RepoFS
+	}
+	return os.DirFS(in.RepoDir)
+}
+
+// resolveOwnership returns the effective ownership of repository files. Only files tracked by git are resolved,
+// so ignored and untracked files, such as build outputs, are skipped. If the repository directory is not set
+// or it's not a git repository, e.g. it's provided by pkg/validator callers, all files except the .git directory
+// are resolved. Other git failures, such as the dubious ownership error when the workspace is not trusted, are returned.
+func resolveOwnership(ctx context.Context, in Input, ws WorkspaceConfig) ([]fileOwnership, error) {
+	var (
+		paths []string
+		err   error
+	)
+	if root, found := gitRootDir(in.RepoDir); found {
+		safeDir := ""
+		if ws.TrustWorkspace {
+			safeDir = root
+		}
+		paths, err = listTrackedFiles(ctx, in.RepoDir, safeDir)
+		if err != nil {
+			if ctxutil.ShouldExit(ctx) {
+				return nil, ctx.Err()
+			}
+			return nil, errors.Wrap(err, "while listing files tracked by git")
+		}
+	} else if paths, err = listAllFiles(ctx, repoFS(in)); err != nil {
+		return nil, err
+	}
+
+	matcher := codeowners.NewMatcher(in.CodeownersEntries)
+	out := make([]fileOwnership, 0, len(paths))
+	for _, p := range paths {
+		own := fileOwnership{Path: p}
+		if entry, found := matcher.Match(p); found {
+			own.Entry = &entry
+		}
+		out = append(out, own)
+	}
+	return out, nil
+}
+
+// gitRootDir returns the absolute path of a given directory or its closest parent which holds the .git entry.
+// Returns false if the directory is not a part of a git repository.
+func gitRootDir(dir string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(abs, ".git")); err == nil {
+			return abs, true
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", false
+		}
+		abs = parent
+	}
+}
+
+// listTrackedFiles returns slash-separated paths of files tracked by git in a given repository directory.
+// If safeDir is not empty, it's marked as safe only for this git invocation, so the global git config is not modified.
+func listTrackedFiles(ctx context.Context, repoDir, safeDir string) ([]string, error) {
+	var args []string
+	if safeDir != "" {
+		args = append(args, "-c", "safe.directory="+safeDir)
+	}
+	args = append(args, "-C", repoDir, "ls-files", "-z")
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stderr = &stderr
+	stdout, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrap(err, strings.TrimSpace(stderr.String()))
+	}
+
+	var paths []string
+	for _, p := range strings.Split(string(stdout), "\x00") {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// listAllFiles returns slash-separated paths of all files in a given file system, except the .git directory.
+func listAllFiles(ctx context.Context, fsys fs.FS) ([]string, error) {
+	var paths []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxutil.ShouldExit(ctx) {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		paths = append(paths, p)
+		return nil
+	})
+	return paths, err
+}
@@ -23,6 +23,11 @@ func TestRespectingCanceledContext(t *testing.T) {
 		check.NewValidSyntax(),
 		check.NewNotOwnedFile(check.NotOwnedFileConfig{}),
 		must(check.NewValidOwner(check.ValidOwnerConfig{Repository: "org/repo"}, nil, true)),
+		must(check.NewPlugin(check.PluginConfig{ID: "plugin", Command: []string{"true"}})),
+		must(check.NewCEL(check.CELConfig{Rules: []check.CELRule{{Name: "all", Expression: "true", Message: "msg"}}})),
+		must(check.NewRequiredOwners(check.RequiredOwnersConfig{Paths: map[string][]string{"*": {"@org/security"}}})),
+		check.NewCodeownersOwned(check.CodeownersOwnedConfig{}),
+		must(check.NewPolicy(check.PolicyConfig{Rules: []check.PolicyRule{{Name: "all", Paths: []string{"*"}, ForbidUsers: true}}})),
 	}
 
 	for _, checker := range checkers {
@@ -0,0 +1,185 @@
+package check
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"go.szostok.io/codeowners-validator/internal/ctxutil"
+	"go.szostok.io/codeowners-validator/internal/ptr"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultPluginTimeout is used when the plugin timeout is not specified.
+const DefaultPluginTimeout = time.Minute
+
+// pluginWaitDelay limits how long the plugin output is read after the plugin is killed. Child processes
+// of shell plugins may inherit the output and keep running, which would block the check otherwise.
+const pluginWaitDelay = time.Second
+
+// PluginConfig describes an external executable executed as a check.
+type PluginConfig struct {
+	// ID is used to select the plugin, e.g. in the CHECKS option.
+	ID string `json:"id"`
+	// Name is printed in the checks results. Defaults to ID.
+	Name string `json:"name"`
+	// Command holds the executable and its arguments.
+	Command []string `json:"command"`
+	// Timeout is a duration, such as 30s. Defaults to DefaultPluginTimeout.
+	Timeout string `json:"timeout"`
+}
+
+type (
+	// pluginInput is written as JSON to the plugin stdin.
+	pluginInput struct {
+		RepoDir           string             `json:"repoDir"`
+		CodeownersEntries []pluginInputEntry `json:"codeownersEntries"`
+	}
+
+	pluginInputEntry struct {
+		LineNo  uint64   `json:"lineNo"`
+		Pattern string   `json:"pattern"`
+		Owners  []string `json:"owners"`
+		Section string   `json:"section,omitempty"`
+	}
+
+	// pluginOutput is read as JSON from the plugin stdout.
+	pluginOutput struct {
+		Issues []pluginOutputIssue `json:"issues"`
+	}
+
+	pluginOutputIssue struct {
+		Severity string  `json:"severity"`
+		LineNo   *uint64 `json:"lineNo"`
+		Message  string  `json:"message"`
+	}
+)
+
+// Plugin executes an external executable as a check. The check input is sent as JSON to its stdin,
+// and issues are read as JSON from its stdout. The executable is started in the repository directory.
+type Plugin struct {
+	name    string
+	command []string
+	timeout time.Duration
+}
+
+// NewPlugin returns a new Plugin instance.
+func NewPlugin(cfg PluginConfig) (*Plugin, error) {
+	if len(cfg.Command) == 0 {
+		return nil, fmt.Errorf("command for plugin %q cannot be empty", cfg.ID)
+	}
+
+	timeout := DefaultPluginTimeout
+	if cfg.Timeout != "" {
+		t, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, errors.Wrapf(err, "while parsing timeout for plugin %q", cfg.ID)
+		}
+		timeout = t
+	}
+
+	name := cfg.Name
+	if name == "" {
+		name = cfg.ID
+	}
+
+	return &Plugin{
+		name:    name,
+		command: cfg.Command,
+		timeout: timeout,
+	}, nil
+}
+
+func (p *Plugin) Check(ctx context.Context, in Input) (Output, error) {
+	if ctxutil.ShouldExit(ctx) {
+		return Output{}, ctx.Err()
+	}
+
+	stdin, err := json.Marshal(p.toPluginInput(in))
+	if err != nil {
+		return Output{}, errors.Wrap(err, "while marshaling plugin input")
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	//nolint:gosec // executing user-defined plugins is the purpose of this check
+	cmd := exec.CommandContext(execCtx, p.command[0], p.command[1:]...)
+	cmd.Dir = in.RepoDir
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = pluginWaitDelay
+
+	err = cmd.Run()
+	switch {
+	case ctxutil.ShouldExit(ctx):
+		return Output{}, ctx.Err()
+	case execCtx.Err() != nil:
+		return Output{}, fmt.Errorf("plugin %q timed out after %v", p.name, p.timeout)
+	case err != nil:
+		return Output{}, errors.Wrapf(err, "while executing plugin %q: %s", p.name, strings.TrimSpace(stderr.String()))
+	}
+
+	var out pluginOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return Output{}, errors.Wrapf(err, "while parsing plugin %q output", p.name)
+	}
+
+	return p.toOutput(out)
+}
+
+func (*Plugin) toPluginInput(in Input) pluginInput {
+	entries := make([]pluginInputEntry, 0, len(in.CodeownersEntries))
+	for _, e := range in.CodeownersEntries {
+		entries = append(entries, pluginInputEntry{
+			LineNo:  e.LineNo,
+			Pattern: e.Pattern,
+			Owners:  e.Owners,
+			Section: e.Section,
+		})
+	}
+	return pluginInput{
+		RepoDir:           in.RepoDir,
+		CodeownersEntries: entries,
+	}
+}
+
+func (p *Plugin) toOutput(out pluginOutput) (Output, error) {
+	var bldr OutputBuilder
+	for _, i := range out.Issues {
+		if i.Message == "" {
+			return Output{}, fmt.Errorf("plugin %q reported an issue without message", p.name)
+		}
+
+		severity := Error
+		if i.Severity != "" {
+			if err := severity.Unmarshal(i.Severity); err != nil {
+				return Output{}, errors.Wrapf(err, "while parsing plugin %q output", p.name)
+			}
+		}
+
+		opts := []ReportIssueOpt{WithSeverity(severity)}
+		if i.LineNo != nil {
+			opts = append(opts, withLineNo(*i.LineNo))
+		}
+		bldr.ReportIssue(i.Message, opts...)
+	}
+	return bldr.Output(), nil
+}
+
+func withLineNo(lineNo uint64) ReportIssueOpt {
+	return func(i *Issue) {
+		i.LineNo = ptr.Uint64Ptr(lineNo)
+	}
+}
+
+func (p *Plugin) Name() string {
+	return p.name
+}
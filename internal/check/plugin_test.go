@@ -0,0 +1,144 @@
+package check_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.szostok.io/codeowners-validator/internal/check"
+	"go.szostok.io/codeowners-validator/internal/ptr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginSuccess(t *testing.T) {
+	// given
+	repoDir := t.TempDir()
+	inputPath := filepath.Join(repoDir, "input.json")
+
+	plugin, err := check.NewPlugin(check.PluginConfig{
+		ID: "house-rules",
+		Command: []string{"sh", "-c", `cat > input.json; echo '{"issues": [
+			{"severity": "warning", "lineNo": 2, "message": "Use team owners"},
+			{"message": "Missing catch-all entry"}
+		]}'`},
+	})
+	require.NoError(t, err)
+
+	in := LoadInput("*.js @pico\n")
+	in.RepoDir = repoDir
+
+	// when
+	out, err := plugin.Check(context.Background(), in)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "house-rules", plugin.Name())
+	assert.Equal(t, []check.Issue{
+		{Severity: check.Warning, LineNo: ptr.Uint64Ptr(2), Message: "Use team owners"},
+		{Severity: check.Error, Message: "Missing catch-all entry"},
+	}, out.Issues)
+
+	gotInput, err := os.ReadFile(inputPath)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"repoDir": "`+repoDir+`",
+		"codeownersEntries": [{"lineNo": 1, "pattern": "*.js", "owners": ["@pico"]}]
+	}`, string(gotInput))
+}
+
+func TestPluginFailures(t *testing.T) {
+	tests := map[string]struct {
+		cfg       check.PluginConfig
+		expErrMsg string
+	}{
+		"Should report non-zero exit code together with stderr": {
+			cfg:       check.PluginConfig{ID: "failing", Command: []string{"sh", "-c", "echo 'boom' >&2; exit 2"}},
+			expErrMsg: `while executing plugin "failing": boom: exit status 2`,
+		},
+		"Should report timeout": {
+			cfg:       check.PluginConfig{ID: "slow", Command: []string{"sleep", "10"}, Timeout: "50ms"},
+			expErrMsg: `plugin "slow" timed out after 50ms`,
+		},
+		"Should report malformed output": {
+			cfg:       check.PluginConfig{ID: "malformed", Name: "Malformed Plugin", Command: []string{"echo", "not-json"}},
+			expErrMsg: `while parsing plugin "Malformed Plugin" output: invalid character 'o' in literal null (expecting 'u')`,
+		},
+		"Should report unknown severity": {
+			cfg:       check.PluginConfig{ID: "severity", Command: []string{"echo", `{"issues": [{"severity": "fatal", "message": "msg"}]}`}},
+			expErrMsg: `while parsing plugin "severity" output: not a valid severity type: "fatal"`,
+		},
+		"Should report issue without message": {
+			cfg:       check.PluginConfig{ID: "empty", Command: []string{"echo", `{"issues": [{}]}`}},
+			expErrMsg: `plugin "empty" reported an issue without message`,
+		},
+	}
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			plugin, err := check.NewPlugin(tc.cfg)
+			require.NoError(t, err)
+
+			in := LoadInput("* @pico")
+			in.RepoDir = t.TempDir()
+
+			// when
+			out, err := plugin.Check(context.Background(), in)
+
+			// then
+			assert.EqualError(t, err, tc.expErrMsg)
+			assert.Empty(t, out)
+		})
+	}
+}
+
+func TestPluginTimeoutWithChildProcesses(t *testing.T) {
+	// given
+	plugin, err := check.NewPlugin(check.PluginConfig{
+		ID: "forking",
+		// the child process inherits the output, so it stays open after the shell is killed
+		Command: []string{"sh", "-c", "sleep 10; echo '{}'"},
+		Timeout: "50ms",
+	})
+	require.NoError(t, err)
+
+	in := LoadInput("* @pico")
+	in.RepoDir = t.TempDir()
+	start := time.Now()
+
+	// when
+	_, err = plugin.Check(context.Background(), in)
+
+	// then
+	assert.EqualError(t, err, `plugin "forking" timed out after 50ms`)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestNewPluginFailures(t *testing.T) {
+	tests := map[string]struct {
+		cfg       check.PluginConfig
+		expErrMsg string
+	}{
+		"Should reject empty command": {
+			cfg:       check.PluginConfig{ID: "empty"},
+			expErrMsg: `command for plugin "empty" cannot be empty`,
+		},
+		"Should reject malformed timeout": {
+			cfg:       check.PluginConfig{ID: "timeout", Command: []string{"true"}, Timeout: "soon"},
+			expErrMsg: `while parsing timeout for plugin "timeout": time: invalid duration "soon"`,
+		},
+	}
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			// when
+			plugin, err := check.NewPlugin(tc.cfg)
+
+			// then
+			assert.EqualError(t, err, tc.expErrMsg)
+			assert.Nil(t, plugin)
+		})
+	}
+}
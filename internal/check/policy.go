@@ -0,0 +1,230 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"go.szostok.io/codeowners-validator/internal/ctxutil"
+	"go.szostok.io/codeowners-validator/pkg/codeowners"
+
+	"github.com/pkg/errors"
+)
+
+// PolicyConfig holds declarative rules for the ownership of repository paths.
+type PolicyConfig struct {
+	Rules []PolicyRule `envconfig:"optional" desc:"The JSON list of policy rules, e.g. [{\"name\": \"security\", \"paths\": [\".github/workflows/**\"], \"requiredOwners\": [\"@org/security\"]}]."`
+	// Workspace is shared with other checks, so it's set by the caller instead of being loaded under this check prefix.
+	Workspace WorkspaceConfig `envconfig:"-"`
+}
+
+// PolicyRule describes ownership requirements for files matching given paths.
+type PolicyRule struct {
+	// Name is printed in the reported issues.
+	Name string `json:"name"`
+	// Paths uses the CODEOWNERS pattern syntax, e.g. services/*/ or .github/workflows/**.
+	Paths []string `json:"paths"`
+	// RequiredOwners must all be the effective owners of each matching file.
+	RequiredOwners []string `json:"requiredOwners"`
+	// OwnerPattern must match at least one effective owner of each matching file, e.g. @acme/svc-*.
+	OwnerPattern string `json:"ownerPattern"`
+	// ForbidUsers disallows individual users, including emails, as effective owners of matching files.
+	ForbidUsers bool `json:"forbidUsers"`
+}
+
+// policyRule is a validated PolicyRule.
+type policyRule struct {
+	PolicyRule
+	paths *codeowners.Matcher
+}
+
+// policyViolation groups files which break the same requirement of the same rule
+// and are owned by the same CODEOWNERS entry.
+type policyViolation struct {
+	requirement int
+	entry       *codeowners.Entry
+	msg         string
+	files       []string
+}
+
+// Policy reports files which effective owners break the configured rules.
+type Policy struct {
+	rules     []policyRule
+	workspace WorkspaceConfig
+}
+
+// NewPolicy returns a new Policy instance.
+func NewPolicy(cfg PolicyConfig) (*Policy, error) {
+	names := map[string]struct{}{}
+	rules := make([]policyRule, 0, len(cfg.Rules))
+	for idx, r := range cfg.Rules {
+		switch {
+		case r.Name == "":
+			return nil, fmt.Errorf("policy rule #%d must have a name", idx+1)
+		case len(r.Paths) == 0:
+			return nil, fmt.Errorf("policy rule %q must have at least one path", r.Name)
+		case len(r.RequiredOwners) == 0 && r.OwnerPattern == "" && !r.ForbidUsers:
+			return nil, fmt.Errorf("policy rule %q must define at least one of requiredOwners, ownerPattern or forbidUsers", r.Name)
+		}
+		if _, found := names[r.Name]; found {
+			return nil, fmt.Errorf("policy rule %q is defined more than once", r.Name)
+		}
+		names[r.Name] = struct{}{}
+
+		if _, err := path.Match(r.OwnerPattern, ""); err != nil {
+			return nil, errors.Wrapf(err, "while parsing owner pattern %q of policy rule %q", r.OwnerPattern, r.Name)
+		}
+
+		var pathEntries []codeowners.Entry
+		for _, p := range r.Paths {
+			pathEntries = append(pathEntries, codeowners.Entry{Pattern: p})
+		}
+		rules = append(rules, policyRule{PolicyRule: r, paths: codeowners.NewMatcher(pathEntries)})
+	}
+
+	return &Policy{rules: rules, workspace: cfg.Workspace}, nil
+}
+
+func (p *Policy) Check(ctx context.Context, in Input) (Output, error) {
+	return p.check(ctx, in, func(rule policyRule) string {
+		return fmt.Sprintf("Policy rule %q", rule.Name)
+	})
+}
+
+// check reports violations of all rules, each issue message starts with the rule description.
+func (p *Policy) check(ctx context.Context, in Input, describeRule func(policyRule) string) (Output, error) {
+	if ctxutil.ShouldExit(ctx) {
+		return Output{}, ctx.Err()
+	}
+
+	var bldr OutputBuilder
+	if len(p.rules) == 0 {
+		return bldr.Output(), nil
+	}
+
+	files, err := resolveOwnership(ctx, in, p.workspace)
+	if err != nil {
+		return Output{}, errors.Wrap(err, "while resolving files ownership")
+	}
+
+	for _, rule := range p.rules {
+		for _, v := range p.evaluate(rule, files) {
+			msg := fmt.Sprintf("%s: %s %s", describeRule(rule), describeFiles(v.files, v.entry == nil), v.msg)
+			if v.entry != nil {
+				bldr.ReportIssue(msg, WithEntry(*v.entry))
+			} else {
+				bldr.ReportIssue(msg)
+			}
+		}
+	}
+
+	return bldr.Output(), nil
+}
+
+func (p *Policy) evaluate(rule policyRule, files []fileOwnership) []policyViolation {
+	grouped := map[string]*policyViolation{}
+	report := func(requirement int, own fileOwnership, msg string) {
+		key := fmt.Sprintf("%d/%s", requirement, msg)
+		if own.Entry != nil {
+			key = fmt.Sprintf("%s/%d", key, own.Entry.LineNo)
+		}
+		if _, found := grouped[key]; !found {
+			grouped[key] = &policyViolation{requirement: requirement, entry: own.Entry, msg: msg}
+		}
+		grouped[key].files = append(grouped[key].files, own.Path)
+	}
+
+	for _, own := range files {
+		if _, found := rule.paths.Match(own.Path); !found {
+			continue
+		}
+
+		owners := own.Owners()
+		for _, required := range rule.RequiredOwners {
+			if !containsOwner(owners, required) {
+				report(0, own, fmt.Sprintf("must be owned by %s", required))
+			}
+		}
+		if rule.OwnerPattern != "" && !matchesAnyOwner(owners, rule.OwnerPattern) {
+			report(1, own, fmt.Sprintf("must be owned by an owner matching %q", rule.OwnerPattern))
+		}
+		if users := individualUsers(owners); rule.ForbidUsers && len(users) > 0 {
+			report(2, own, fmt.Sprintf("must not be owned by individual users, found %s", strings.Join(users, ", ")))
+		}
+	}
+
+	out := make([]policyViolation, 0, len(grouped))
+	for _, v := range grouped {
+		sort.Strings(v.files)
+		out = append(out, *v)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].requirement != out[j].requirement {
+			return out[i].requirement < out[j].requirement
+		}
+		if li, lj := lineNo(out[i].entry), lineNo(out[j].entry); li != lj {
+			return li < lj
+		}
+		return out[i].msg < out[j].msg
+	})
+	return out
+}
+
+// describeFiles returns the list of all given files, e.g. "a.go", "b.go" and "c.go",
+// so each file which breaks the rule is named.
+func describeFiles(files []string, unowned bool) string {
+	quoted := make([]string, 0, len(files))
+	for _, f := range files {
+		quoted = append(quoted, fmt.Sprintf("%q", f))
+	}
+	out := quoted[len(quoted)-1]
+	if len(quoted) > 1 {
+		out = fmt.Sprintf("%s and %s", strings.Join(quoted[:len(quoted)-1], ", "), out)
+	}
+	if unowned {
+		out += " not owned by any entry"
+	}
+	return out
+}
+
+func lineNo(e *codeowners.Entry) uint64 {
+	if e == nil {
+		return 0
+	}
+	return e.LineNo
+}
+
+func containsOwner(owners []string, owner string) bool {
+	for _, o := range owners {
+		if strings.EqualFold(o, owner) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyOwner(owners []string, pattern string) bool {
+	for _, o := range owners {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(o)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// individualUsers returns owners which are not teams, such as @user or user@example.com.
+func individualUsers(owners []string) []string {
+	var out []string
+	for _, o := range owners {
+		if !isGitHubTeam(o) {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+func (*Policy) Name() string {
+	return "[Experimental] Policy Checker"
+}
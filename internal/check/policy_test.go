@@ -0,0 +1,243 @@
+package check_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"go.szostok.io/codeowners-validator/internal/check"
+	"go.szostok.io/codeowners-validator/internal/ptr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy(t *testing.T) {
+	// given
+	repo := fstest.MapFS{
+		".git/config":                    &fstest.MapFile{},
+		".github/workflows/ci.yaml":      &fstest.MapFile{},
+		".github/workflows/release.yaml": &fstest.MapFile{},
+		"services/billing/main.go":       &fstest.MapFile{},
+		"services/auth/main.go":          &fstest.MapFile{},
+		"services/auth/README.md":        &fstest.MapFile{},
+		"infra/main.tf":                  &fstest.MapFile{},
+		"infra/modules/vpc.tf":           &fstest.MapFile{},
+		"docs/README.md":                 &fstest.MapFile{},
+	}
+
+	policy, err := check.NewPolicy(check.PolicyConfig{Rules: []check.PolicyRule{
+		{Name: "services", Paths: []string{"services/*/"}, OwnerPattern: "@acme/svc-*"},
+		{Name: "workflows", Paths: []string{".github/workflows/**"}, RequiredOwners: []string{"@acme/security"}},
+		{Name: "infra", Paths: []string{"/infra/"}, ForbidUsers: true},
+	}})
+	require.NoError(t, err)
+
+	in := LoadInput(`
+/services/billing/ @acme/svc-billing
+/services/auth/    @acme/auth-devs
+/infra/            @acme/platform @bob
+/infra/modules/    @acme/platform
+/.github/          @acme/security
+/.github/workflows/release.yaml @acme/release
+`)
+	in.RepoFS = repo
+
+	// when
+	out, err := policy.Check(context.Background(), in)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []check.Issue{
+		{
+			Severity: check.Error,
+			LineNo:   ptr.Uint64Ptr(3),
+			Message:  `Policy rule "services": "services/auth/README.md" and "services/auth/main.go" must be owned by an owner matching "@acme/svc-*"`,
+		},
+		{
+			Severity: check.Error,
+			LineNo:   ptr.Uint64Ptr(7),
+			Message:  `Policy rule "workflows": ".github/workflows/release.yaml" must be owned by @acme/security`,
+		},
+		{
+			Severity: check.Error,
+			LineNo:   ptr.Uint64Ptr(4),
+			Message:  `Policy rule "infra": "infra/main.tf" must not be owned by individual users, found @bob`,
+		},
+	}, out.Issues)
+}
+
+func TestPolicyUnownedFiles(t *testing.T) {
+	// given
+	policy, err := check.NewPolicy(check.PolicyConfig{Rules: []check.PolicyRule{
+		{Name: "docker", Paths: []string{"Dockerfile"}, RequiredOwners: []string{"@acme/security"}},
+	}})
+	require.NoError(t, err)
+
+	in := LoadInput("/docs/ @acme/docs")
+	in.RepoFS = fstest.MapFS{"Dockerfile": &fstest.MapFile{}, "app/Dockerfile": &fstest.MapFile{}}
+
+	// when
+	out, err := policy.Check(context.Background(), in)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []check.Issue{
+		{
+			Severity: check.Error,
+			Message:  `Policy rule "docker": "Dockerfile" and "app/Dockerfile" not owned by any entry must be owned by @acme/security`,
+		},
+	}, out.Issues)
+}
+
+func TestPolicyTrackedFiles(t *testing.T) {
+	// given
+	policy, err := check.NewPolicy(check.PolicyConfig{Rules: []check.PolicyRule{
+		{Name: "docker", Paths: []string{"Dockerfile"}, RequiredOwners: []string{"@acme/security"}},
+	}})
+	require.NoError(t, err)
+
+	in := LoadInput("/docs/ @acme/docs")
+	in.RepoDir = initGitRepo(t, map[string]string{
+		".gitignore": "build/\n",
+		"Dockerfile": "FROM scratch",
+	})
+	// ignored and untracked files are created after the commit
+	for _, dir := range []string{"build", "tmp"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(in.RepoDir, dir), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(in.RepoDir, dir, "Dockerfile"), nil, 0o600))
+	}
+	in.RepoFS = os.DirFS(in.RepoDir)
+
+	// when
+	out, err := policy.Check(context.Background(), in)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []check.Issue{
+		{
+			Severity: check.Error,
+			Message:  `Policy rule "docker": "Dockerfile" not owned by any entry must be owned by @acme/security`,
+		},
+	}, out.Issues, "ignored and untracked files should be skipped")
+}
+
+func TestPolicyGitFailure(t *testing.T) {
+	// given
+	policy, err := check.NewPolicy(check.PolicyConfig{Rules: []check.PolicyRule{
+		{Name: "docker", Paths: []string{"Dockerfile"}, RequiredOwners: []string{"@acme/security"}},
+	}})
+	require.NoError(t, err)
+
+	in := LoadInput("/docs/ @acme/docs")
+	in.RepoDir = t.TempDir()
+	// the .git entry is not a valid git directory, so git fails the same way as for untrusted repositories
+	require.NoError(t, os.WriteFile(filepath.Join(in.RepoDir, ".git"), []byte("gitdir: ./missing"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(in.RepoDir, "Dockerfile"), nil, 0o600))
+
+	// when
+	out, err := policy.Check(context.Background(), in)
+
+	// then
+	assert.ErrorContains(t, err, "while listing files tracked by git")
+	assert.Empty(t, out.Issues, "files should not be resolved from the file system")
+}
+
+func TestPolicyTrustWorkspace(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("changing the repository owner requires root")
+	}
+
+	// given
+	repoDir := initGitRepo(t, map[string]string{"Dockerfile": "FROM scratch"})
+	// the repository belongs to another user, the same way as the workspace mounted into the GitHub Action container
+	require.NoError(t, filepath.WalkDir(repoDir, func(path string, _ os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, 4242, 4242)
+	}))
+	// the global git config doesn't mark any directory as safe
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	newPolicy := func(trust bool) *check.Policy {
+		policy, err := check.NewPolicy(check.PolicyConfig{
+			Rules: []check.PolicyRule{
+				{Name: "docker", Paths: []string{"Dockerfile"}, RequiredOwners: []string{"@acme/security"}},
+			},
+			Workspace: check.WorkspaceConfig{TrustWorkspace: trust},
+		})
+		require.NoError(t, err)
+		return policy
+	}
+
+	in := LoadInput("/docs/ @acme/docs")
+	in.RepoDir = repoDir
+
+	t.Run("Should fail on dubious ownership if workspace is not trusted", func(t *testing.T) {
+		// when
+		_, err := newPolicy(false).Check(context.Background(), in)
+
+		// then
+		assert.ErrorContains(t, err, "dubious ownership")
+	})
+
+	t.Run("Should list files if workspace is trusted", func(t *testing.T) {
+		// when
+		out, err := newPolicy(true).Check(context.Background(), in)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []check.Issue{
+			{
+				Severity: check.Error,
+				Message:  `Policy rule "docker": "Dockerfile" not owned by any entry must be owned by @acme/security`,
+			},
+		}, out.Issues)
+		assert.NoFileExists(t, filepath.Join(os.Getenv("HOME"), ".gitconfig"), "global git config should not be modified")
+	})
+}
+
+func TestNewPolicyFailures(t *testing.T) {
+	tests := map[string]struct {
+		rules     []check.PolicyRule
+		expErrMsg string
+	}{
+		"Should require name": {
+			rules:     []check.PolicyRule{{Paths: []string{"*"}, ForbidUsers: true}},
+			expErrMsg: "policy rule #1 must have a name",
+		},
+		"Should require paths": {
+			rules:     []check.PolicyRule{{Name: "all", ForbidUsers: true}},
+			expErrMsg: `policy rule "all" must have at least one path`,
+		},
+		"Should require at least one requirement": {
+			rules:     []check.PolicyRule{{Name: "all", Paths: []string{"*"}}},
+			expErrMsg: `policy rule "all" must define at least one of requiredOwners, ownerPattern or forbidUsers`,
+		},
+		"Should reject duplicated names": {
+			rules: []check.PolicyRule{
+				{Name: "all", Paths: []string{"*"}, ForbidUsers: true},
+				{Name: "all", Paths: []string{"*"}, ForbidUsers: true},
+			},
+			expErrMsg: `policy rule "all" is defined more than once`,
+		},
+		"Should reject malformed owner pattern": {
+			rules:     []check.PolicyRule{{Name: "all", Paths: []string{"*"}, OwnerPattern: "@acme/[svc"}},
+			expErrMsg: `while parsing owner pattern "@acme/[svc" of policy rule "all": syntax error in pattern`,
+		},
+	}
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			// when
+			policy, err := check.NewPolicy(check.PolicyConfig{Rules: tc.rules})
+
+			// then
+			assert.EqualError(t, err, tc.expErrMsg)
+			assert.Nil(t, policy)
+		})
+	}
+}
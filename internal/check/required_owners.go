@@ -0,0 +1,54 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// RequiredOwnersConfig maps sensitive paths to their required owners.
+type RequiredOwnersConfig struct {
+	Paths map[string][]string `envconfig:"optional" desc:"The JSON object which maps paths to the owners they require, e.g. {\".github/**\": [\"@org/security\"], \"Dockerfile\": [\"@org/security\"]}."`
+	// Workspace is shared with other checks, so it's set by the caller instead of being loaded under this check prefix.
+	Workspace WorkspaceConfig `envconfig:"-"`
+}
+
+// RequiredOwners reports files which effective owners don't include the owners required for their paths.
+// Each path is evaluated as a policy rule, so violations are grouped per CODEOWNERS entry the same way.
+type RequiredOwners struct {
+	policy *Policy
+}
+
+// NewRequiredOwners returns a new RequiredOwners instance.
+func NewRequiredOwners(cfg RequiredOwnersConfig) (*RequiredOwners, error) {
+	patterns := make([]string, 0, len(cfg.Paths))
+	for p := range cfg.Paths {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+
+	rules := make([]PolicyRule, 0, len(patterns))
+	for _, p := range patterns {
+		owners := cfg.Paths[p]
+		if len(owners) == 0 {
+			return nil, fmt.Errorf("required owners for path %q cannot be empty", p)
+		}
+		rules = append(rules, PolicyRule{Name: p, Paths: []string{p}, RequiredOwners: owners})
+	}
+
+	policy, err := NewPolicy(PolicyConfig{Rules: rules, Workspace: cfg.Workspace})
+	if err != nil {
+		return nil, err
+	}
+	return &RequiredOwners{policy: policy}, nil
+}
+
+func (r *RequiredOwners) Check(ctx context.Context, in Input) (Output, error) {
+	return r.policy.check(ctx, in, func(rule policyRule) string {
+		return fmt.Sprintf("Required owners of %q", rule.Name)
+	})
+}
+
+func (*RequiredOwners) Name() string {
+	return "[Experimental] Required Owners Checker"
+}
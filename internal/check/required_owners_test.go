@@ -0,0 +1,133 @@
+package check_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"go.szostok.io/codeowners-validator/internal/check"
+	"go.szostok.io/codeowners-validator/internal/ptr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredOwners(t *testing.T) {
+	// given
+	sut, err := check.NewRequiredOwners(check.RequiredOwnersConfig{Paths: map[string][]string{
+		"CODEOWNERS": {"@org/security"},
+		".github/**": {"@org/security"},
+		"Dockerfile": {"@org/security", "@org/platform"},
+		"/docs/":     {"@org/docs"},
+	}})
+	require.NoError(t, err)
+
+	in := LoadInput(`
+*                   @org/security @org/platform
+/.github/workflows/ @org/ci
+/app/               @org/app
+/app/Dockerfile     @org/platform
+`)
+	in.RepoFS = fstest.MapFS{
+		".github/CODEOWNERS":          &fstest.MapFile{},
+		".github/workflows/ci.yaml":   &fstest.MapFile{},
+		"Dockerfile":                  &fstest.MapFile{},
+		"app/Dockerfile":              &fstest.MapFile{},
+		"app/main.go":                 &fstest.MapFile{},
+		".github/workflows/cd.yaml":   &fstest.MapFile{},
+		".github/workflows/lint.yaml": &fstest.MapFile{},
+	}
+
+	// when
+	out, err := sut.Check(context.Background(), in)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []check.Issue{
+		{
+			Severity: check.Error,
+			LineNo:   ptr.Uint64Ptr(3),
+			Message:  `Required owners of ".github/**": ".github/workflows/cd.yaml", ".github/workflows/ci.yaml" and ".github/workflows/lint.yaml" must be owned by @org/security`,
+		},
+		{
+			Severity: check.Error,
+			LineNo:   ptr.Uint64Ptr(5),
+			Message:  `Required owners of "Dockerfile": "app/Dockerfile" must be owned by @org/security`,
+		},
+	}, out.Issues)
+}
+
+func TestRequiredOwnersUnownedFiles(t *testing.T) {
+	// given
+	sut, err := check.NewRequiredOwners(check.RequiredOwnersConfig{Paths: map[string][]string{
+		"*.tf": {"@org/platform"},
+	}})
+	require.NoError(t, err)
+
+	in := LoadInput(`
+/docs/   @org/docs
+/infra/
+`)
+	in.RepoFS = fstest.MapFS{
+		"main.tf":       &fstest.MapFile{},
+		"infra/vpc.tf":  &fstest.MapFile{},
+		"docs/index.md": &fstest.MapFile{},
+	}
+
+	// when
+	out, err := sut.Check(context.Background(), in)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []check.Issue{
+		{
+			Severity: check.Error,
+			Message:  `Required owners of "*.tf": "main.tf" not owned by any entry must be owned by @org/platform`,
+		},
+		{
+			Severity: check.Error,
+			LineNo:   ptr.Uint64Ptr(3),
+			Message:  `Required owners of "*.tf": "infra/vpc.tf" must be owned by @org/platform`,
+		},
+	}, out.Issues)
+}
+
+func TestRequiredOwnersTrackedFiles(t *testing.T) {
+	// given
+	sut, err := check.NewRequiredOwners(check.RequiredOwnersConfig{Paths: map[string][]string{
+		"Dockerfile": {"@org/security"},
+	}})
+	require.NoError(t, err)
+
+	repoDir := initGitRepo(t, map[string]string{
+		".gitignore": "build/\n",
+		"Dockerfile": "FROM scratch",
+	})
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "build"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "build", "Dockerfile"), nil, 0o600))
+
+	in := LoadInput(`
+/build/     @org/platform
+/Dockerfile @org/security
+`)
+	in.RepoDir = repoDir
+	in.RepoFS = os.DirFS(repoDir)
+
+	// when
+	out, err := sut.Check(context.Background(), in)
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, out.Issues, "gitignored build/Dockerfile should be skipped")
+}
+
+func TestNewRequiredOwnersFailure(t *testing.T) {
+	// when
+	sut, err := check.NewRequiredOwners(check.RequiredOwnersConfig{Paths: map[string][]string{"Dockerfile": {}}})
+
+	// then
+	assert.EqualError(t, err, `required owners for path "Dockerfile" cannot be empty`)
+	assert.Nil(t, sut)
+}
@@ -6,55 +6,91 @@ import (
 	"net/http"
 	"net/mail"
 	"strings"
+	"sync"
 
 	"go.szostok.io/codeowners-validator/internal/ctxutil"
+	"go.szostok.io/codeowners-validator/internal/github"
+	"go.szostok.io/codeowners-validator/pkg/codeowners"
 
-	"github.com/google/go-github/v41/github"
 	"github.com/pkg/errors"
 )
 
-const scopeHeader = "X-OAuth-Scopes"
-
-var reqScopes = map[github.Scope]struct{}{
-	github.ScopeReadOrg: {},
+var reqScopes = map[string]struct{}{
+	"read:org": {},
 }
 
 type ValidOwnerConfig struct {
 	// Repository represents the GitHub repository against which
 	// the external checks like teams and members validation should be executed.
 	// It is in form 'owner/repository'.
-	Repository string
+	Repository string `desc:"The owner and repository name separated by slash, e.g. gh-codeowners/codeowners-samples."`
 	// IgnoredOwners contains a list of owners that should not be validated.
 	// Defaults to @ghost.
 	// More info about the @ghost user: https://docs.github.com/en/free-pro-team@latest/github/setting-up-and-managing-your-github-user-account/deleting-your-user-account
 	// Tip on how @ghost can be used: https://github.community/t5/How-to-use-Git-and-GitHub/CODEOWNERS-file-with-a-NOT-file-type-condition/m-p/31013/highlight/true#M8523
-	IgnoredOwners []string `envconfig:"default=@ghost"`
+	IgnoredOwners []string `envconfig:"default=@ghost" desc:"The comma-separated list of owners that should not be validated."`
 	// AllowUnownedPatterns specifies whether CODEOWNERS may have unowned files. For example:
 	//
 	//  /infra/oncall-rotator/                    @sre-team
 	//  /infra/oncall-rotator/oncall-config.yml
 	//
 	//  The `/infra/oncall-rotator/oncall-config.yml` this file is not owned by anyone.
-	AllowUnownedPatterns bool `envconfig:"default=true"`
+	AllowUnownedPatterns bool `envconfig:"default=true" desc:"Specifies whether CODEOWNERS may have unowned files."`
 	// OwnersMustBeTeams specifies whether owners must be teams in the same org as the repository
-	OwnersMustBeTeams bool `envconfig:"default=false"`
+	OwnersMustBeTeams bool `envconfig:"default=false" desc:"Specifies whether only teams are allowed as owners of files."`
+	// AllowOutsideCollaborators specifies whether users who are not organization members,
+	// but have write access to the repository, are valid owners.
+	AllowOutsideCollaborators bool `envconfig:"default=true" desc:"Specifies whether outside collaborators with write access to the repository are allowed as owners."`
+	// Concurrency limits the number of owners validated at the same time.
+	Concurrency int `envconfig:"default=10" desc:"The maximum number of owners validated concurrently."`
+	// UnverifiedSeverity is used for owners which could not be verified, e.g. because the GitHub API is unavailable.
+	UnverifiedSeverity SeverityType `envconfig:"default=error" desc:"Severity of issues reported for owners that could not be verified because GitHub API calls failed. Possible values are error and warning."`
 }
 
 // ValidOwner validates each owner
 type ValidOwner struct {
-	ghClient             *github.Client
+	backend              github.Backend
 	checkScopes          bool
-	orgMembers           *map[string]struct{}
 	orgName              string
-	orgTeams             []*github.Team
 	orgRepoName          string
 	ignOwners            map[string]struct{}
 	allowUnownedPatterns bool
 	ownersMustBeTeams    bool
+	allowOutsideCollabs  bool
+	concurrency          int
+	unverifiedSeverity   SeverityType
+}
+
+// orgData holds the organization data fetched lazily during a single check execution.
+// It's shared by concurrent validations of the execution, so each part is fetched only once.
+type orgData struct {
+	ownerTypeOnce sync.Once
+	ownerType     github.AccountType
+
+	teamsOnce sync.Once
+	teams     []string
+	teamsErr  *validateError
+
+	membersOnce sync.Once
+	members     map[string]struct{}
+	membersErr  *validateError
+
+	// referencedUsers holds logins of all users referenced in the CODEOWNERS file, so they can be fetched at once.
+	referencedUsers []string
+	usersOnce       sync.Once
+	users           map[string]github.AccountType
+	usersErr        *validateError
+}
+
+// ownerValidation describes the validation of an owner, or a missing owner when the owner is empty.
+// Issues are reported for the entry in which the owner is used for the first time.
+type ownerValidation struct {
+	entry codeowners.Entry
+	owner string
 }
 
 // NewValidOwner returns new instance of the ValidOwner
-func NewValidOwner(cfg ValidOwnerConfig, ghClient *github.Client, checkScopes bool) (*ValidOwner, error) {
+func NewValidOwner(cfg ValidOwnerConfig, backend github.Backend, checkScopes bool) (*ValidOwner, error) {
 	split := strings.Split(cfg.Repository, "/")
 	if len(split) != 2 {
 		return nil, errors.Errorf("Wrong repository name. Expected pattern 'owner/repository', got '%s'", cfg.Repository)
@@ -65,14 +101,27 @@ func NewValidOwner(cfg ValidOwnerConfig, ghClient *github.Client, checkScopes bo
 		ignOwners[n] = struct{}{}
 	}
 
+	concurrency := cfg.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	unverifiedSeverity := cfg.UnverifiedSeverity
+	if unverifiedSeverity == 0 {
+		unverifiedSeverity = Error
+	}
+
 	return &ValidOwner{
-		ghClient:             ghClient,
+		backend:              backend,
 		checkScopes:          checkScopes,
 		orgName:              split[0],
 		orgRepoName:          split[1],
 		ignOwners:            ignOwners,
 		allowUnownedPatterns: cfg.AllowUnownedPatterns,
 		ownersMustBeTeams:    cfg.OwnersMustBeTeams,
+		allowOutsideCollabs:  cfg.AllowOutsideCollaborators,
+		concurrency:          concurrency,
+		unverifiedSeverity:   unverifiedSeverity,
 	}, nil
 }
 
@@ -88,22 +137,56 @@ func NewValidOwner(cfg ValidOwnerConfig, ghClient *github.Client, checkScopes bo
 // - if GitHub user then check if have GitHub account
 // - if GitHub user then check if he/she is in organization
 // - if org team then check if exists in organization
+//
+// Unique owners are validated concurrently, but issues are reported in the order of CODEOWNERS lines.
+// If some GitHub data cannot be fetched, the remaining owners are still validated,
+// and owners which depend on the missing data are reported as not verified.
 func (v *ValidOwner) Check(ctx context.Context, in Input) (Output, error) {
+	if ctxutil.ShouldExit(ctx) {
+		return Output{}, ctx.Err()
+	}
+
+	org := &orgData{referencedUsers: v.referencedUserLogins(in.CodeownersEntries)}
+
+	validations := v.planValidations(in.CodeownersEntries)
+	results := v.validateConcurrently(ctx, org, validations)
+	if ctxutil.ShouldExit(ctx) {
+		return Output{}, ctx.Err()
+	}
+
 	var bldr OutputBuilder
+	for idx, val := range validations {
+		if val.owner == "" {
+			bldr.ReportIssue("Missing owner, at least one owner is required", WithEntry(val.entry), WithSeverity(Warning))
+			continue
+		}
 
-	checkedOwners := map[string]struct{}{}
+		switch err := results[idx]; {
+		case err == nil:
+		case err.unverified:
+			msg := fmt.Sprintf("Could not verify owner %q: %s", val.owner, err.msg)
+			bldr.ReportIssue(msg, WithEntry(val.entry), WithSeverity(v.unverifiedSeverity))
+		default:
+			bldr.ReportIssue(err.msg, WithEntry(val.entry))
+		}
+	}
+
+	return bldr.Output(), nil
+}
 
-	for _, entry := range in.CodeownersEntries {
+// planValidations returns validations in the order of CODEOWNERS lines. Each owner is validated only once.
+func (v *ValidOwner) planValidations(entries []codeowners.Entry) []ownerValidation {
+	var (
+		out           []ownerValidation
+		checkedOwners = map[string]struct{}{}
+	)
+	for _, entry := range entries {
 		if len(entry.Owners) == 0 && !v.allowUnownedPatterns {
-			bldr.ReportIssue("Missing owner, at least one owner is required", WithEntry(entry), WithSeverity(Warning))
+			out = append(out, ownerValidation{entry: entry})
 			continue
 		}
 
 		for _, ownerName := range entry.Owners {
-			if ctxutil.ShouldExit(ctx) {
-				return Output{}, ctx.Err()
-			}
-
 			if v.isIgnoredOwner(ownerName) {
 				continue
 			}
@@ -111,19 +194,55 @@ func (v *ValidOwner) Check(ctx context.Context, in Input) (Output, error) {
 			if _, alreadyChecked := checkedOwners[ownerName]; alreadyChecked {
 				continue
 			}
+			checkedOwners[ownerName] = struct{}{}
 
-			validFn := v.selectValidateFn(ownerName)
-			if err := validFn(ctx, ownerName); err != nil {
-				bldr.ReportIssue(err.msg, WithEntry(entry))
-				if err.permanent { // Doesn't make sense to process further
-					return bldr.Output(), nil
+			out = append(out, ownerValidation{entry: entry, owner: ownerName})
+		}
+	}
+	return out
+}
+
+// validateConcurrently validates owners using a bounded pool of workers and returns errors indexed as validations.
+func (v *ValidOwner) validateConcurrently(ctx context.Context, org *orgData, validations []ownerValidation) []*validateError {
+	var (
+		results = make([]*validateError, len(validations))
+		jobs    = make(chan int)
+		wg      sync.WaitGroup
+	)
+
+	for w := 0; w < v.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				// select picks a random ready case, so a canceled context may still dispatch jobs
+				if ctx.Err() != nil {
+					continue
 				}
+				owner := validations[idx].owner
+				results[idx] = v.selectValidateFn(owner)(ctx, org, owner)
 			}
-			checkedOwners[ownerName] = struct{}{}
+		}()
+	}
+
+dispatch:
+	for idx, val := range validations {
+		if val.owner == "" {
+			continue
+		}
+		if ctx.Err() != nil {
+			break dispatch
+		}
+		select {
+		case jobs <- idx:
+		case <-ctx.Done():
+			break dispatch
 		}
 	}
+	close(jobs)
+	wg.Wait()
 
-	return bldr.Output(), nil
+	return results
 }
 
 func isEmailAddress(s string) bool {
@@ -147,14 +266,14 @@ func (v *ValidOwner) isIgnoredOwner(name string) bool {
 	return found
 }
 
-func (v *ValidOwner) selectValidateFn(name string) func(context.Context, string) *validateError {
+func (v *ValidOwner) selectValidateFn(name string) func(context.Context, *orgData, string) *validateError {
 	switch {
 	case v.ownersMustBeTeams:
-		return func(ctx context.Context, s string) *validateError {
+		return func(ctx context.Context, org *orgData, s string) *validateError {
 			if !isGitHubTeam(name) {
 				return newValidateError("Only team owners allowed and %q is not a team", name)
 			}
-			return v.validateTeam(ctx, s)
+			return v.validateTeam(ctx, org, s)
 		}
 	case isGitHubTeam(name):
 		return v.validateTeam
@@ -162,68 +281,71 @@ func (v *ValidOwner) selectValidateFn(name string) func(context.Context, string)
 		return v.validateGitHubUser
 	case isEmailAddress(name):
 		// TODO(mszostok): try to check if e-mail really exists
-		return func(context.Context, string) *validateError { return nil }
+		return func(context.Context, *orgData, string) *validateError { return nil }
 	default:
-		return func(_ context.Context, name string) *validateError {
+		return func(_ context.Context, _ *orgData, name string) *validateError {
 			return newValidateError("Not valid owner definition %q", name)
 		}
 	}
 }
 
-func (v *ValidOwner) initOrgListTeams(ctx context.Context) *validateError {
-	var teams []*github.Team
-	req := &github.ListOptions{
-		PerPage: 100,
-	}
-	for {
-		resultPage, resp, err := v.ghClient.Teams.ListTeams(ctx, v.orgName, req)
-		if err != nil { // TODO(mszostok): implement retry?
-			switch err := err.(type) {
-			case *github.ErrorResponse:
-				if err.Response.StatusCode == http.StatusUnauthorized {
-					return newValidateError("Teams for organization %q could not be queried. Requires GitHub authorization.", v.orgName)
-				}
-				return newValidateError("HTTP error occurred while calling GitHub: %v", err)
-			case *github.RateLimitError:
-				return newValidateError("GitHub rate limit reached: %v", err.Message)
-			default:
-				return newValidateError("Unknown error occurred while calling GitHub: %v", err)
-			}
-		}
-		teams = append(teams, resultPage...)
-		if resp.NextPage == 0 {
-			break
-		}
-		req.Page = resp.NextPage
+func (v *ValidOwner) initOwnerType(ctx context.Context, org *orgData) {
+	ownerType, err := v.backend.OwnerType(ctx, v.orgName, v.orgRepoName)
+	if err != nil {
+		// the repository is treated as owned by an organization, so owners are still validated,
+		// and if it's not, the failed organization lookups are reported as unverified owners
+		org.ownerType = github.OrganizationAccount
+		return
 	}
 
-	v.orgTeams = teams
+	org.ownerType = ownerType
+}
 
-	return nil
+// isPersonalRepo returns true if the repository is owned by a personal account, which has neither teams nor members.
+func (v *ValidOwner) isPersonalRepo(ctx context.Context, org *orgData) bool {
+	org.ownerTypeOnce.Do(func() { v.initOwnerType(ctx, org) })
+	return org.ownerType == github.UserAccount
 }
 
-func (v *ValidOwner) validateTeam(ctx context.Context, name string) *validateError {
-	if v.orgTeams == nil {
-		if err := v.initOrgListTeams(ctx); err != nil {
-			return err.AsPermanent()
+func (v *ValidOwner) initOrgListTeams(ctx context.Context, org *orgData) {
+	teams, err := v.backend.Teams(ctx, v.orgName)
+	if err != nil {
+		if apiStatusCode(err) == http.StatusUnauthorized {
+			org.teamsErr = newValidateError("Teams for organization %q could not be queried. Requires GitHub authorization.", v.orgName).AsUnverified()
+			return
 		}
+		org.teamsErr = newValidateError("%s", describeAPIError(err)).AsUnverified()
+		return
+	}
+
+	org.teams = teams
+}
+
+func (v *ValidOwner) validateTeam(ctx context.Context, org *orgData, name string) *validateError {
+	if v.isPersonalRepo(ctx, org) {
+		return newValidateError("Team %q is not allowed as the repository %s/%s is owned by a personal account.", name, v.orgName, v.orgRepoName)
+	}
+
+	org.teamsOnce.Do(func() { v.initOrgListTeams(ctx, org) })
+	if org.teamsErr != nil {
+		return org.teamsErr
 	}
 
 	// called after validation it's safe to work on `parts` slice
 	parts := strings.SplitN(name, "/", 2)
-	org := parts[0]
-	org = strings.TrimPrefix(org, "@")
+	teamOrg := parts[0]
+	teamOrg = strings.TrimPrefix(teamOrg, "@")
 	team := parts[1]
 
 	// GitHub normalizes name before comparison
-	if !strings.EqualFold(org, v.orgName) {
+	if !strings.EqualFold(teamOrg, v.orgName) {
 		return newValidateError("Team %q does not belong to %q organization.", name, v.orgName)
 	}
 
 	teamExists := func() bool {
-		for _, v := range v.orgTeams {
+		for _, slug := range org.teams {
 			// GitHub normalizes name before comparison
-			if strings.EqualFold(v.GetSlug(), team) {
+			if strings.EqualFold(slug, team) {
 				return true
 			}
 		}
@@ -231,57 +353,26 @@ func (v *ValidOwner) validateTeam(ctx context.Context, name string) *validateErr
 	}
 
 	if !teamExists() {
-		return newValidateError("Team %q does not exist in organization %q.", name, org)
-	}
-
-	// repo contains the permissions for the team slug given
-	// TODO(mszostok): Switch to GraphQL API, see:
-	//   https://github.com/mszostok/codeowners-validator/pull/62#discussion_r561273525
-	repo, _, err := v.ghClient.Teams.IsTeamRepoBySlug(ctx, v.orgName, team, org, v.orgRepoName)
-	if err != nil { // TODO(mszostok): implement retry?
-		switch err := err.(type) {
-		case *github.ErrorResponse:
-			switch err.Response.StatusCode {
-			case http.StatusUnauthorized:
-				return newValidateError(
-					"Team permissions information for %q/%q could not be queried. Requires GitHub authorization.",
-					org, v.orgRepoName)
-			case http.StatusNotFound:
-				return newValidateError(
-					"Team %q does not have permissions associated with the repository %q.",
-					team, v.orgRepoName)
-			default:
-				return newValidateError("HTTP error occurred while calling GitHub: %v", err)
-			}
-		case *github.RateLimitError:
-			return newValidateError("GitHub rate limit reached: %v", err.Message)
-		default:
-			return newValidateError("Unknown error occurred while calling GitHub: %v", err)
-		}
+		return newValidateError("Team %q does not exist in organization %q.", name, teamOrg)
 	}
 
-	teamHasWritePermission := func() bool {
-		for k, v := range repo.GetPermissions() {
-			if !v {
-				continue
-			}
-
-			switch k {
-			case
-				"admin",
-				"maintain",
-				"push":
-				return true
-			case
-				"pull",
-				"triage":
-			}
+	perm, err := v.backend.TeamRepoPermission(ctx, v.orgName, team, v.orgRepoName)
+	if err != nil {
+		if apiStatusCode(err) == http.StatusUnauthorized {
+			return newValidateError(
+				"Team permissions information for %q/%q could not be queried. Requires GitHub authorization.",
+				teamOrg, v.orgRepoName).AsUnverified()
 		}
+		return newValidateError("%s", describeAPIError(err)).AsUnverified()
+	}
 
-		return false
+	if perm == github.PermissionNone {
+		return newValidateError(
+			"Team %q does not have permissions associated with the repository %q.",
+			team, v.orgRepoName)
 	}
 
-	if !teamHasWritePermission() {
+	if !perm.CanReview() {
 		return newValidateError(
 			"Team %q cannot review PRs on %q as neither it nor any parent team has write permissions.",
 			team, v.orgRepoName)
@@ -290,67 +381,129 @@ func (v *ValidOwner) validateTeam(ctx context.Context, name string) *validateErr
 	return nil
 }
 
-func (v *ValidOwner) validateGitHubUser(ctx context.Context, name string) *validateError {
-	if v.orgMembers == nil { // TODO(mszostok): lazy init, make it more robust.
-		if err := v.initOrgListMembers(ctx); err != nil {
-			return newValidateError("Cannot initialize organization member list: %v", err).AsPermanent()
+func (v *ValidOwner) validateGitHubUser(ctx context.Context, org *orgData, name string) *validateError {
+	personal := v.isPersonalRepo(ctx, org)
+
+	// the membership is required only if outside collaborators are not allowed, otherwise it's used only
+	// to describe the missing permissions, so its failure doesn't stop the validation
+	membersKnown := false
+	if !personal {
+		org.membersOnce.Do(func() { v.initOrgListMembers(ctx, org) })
+		if org.membersErr != nil && !v.allowOutsideCollabs {
+			return org.membersErr
 		}
+		membersKnown = org.membersErr == nil
+	}
+
+	org.usersOnce.Do(func() { v.initExistingUsers(ctx, org) })
+	if org.usersErr != nil {
+		return org.usersErr
 	}
 
 	userName := strings.TrimPrefix(name, "@")
-	_, _, err := v.ghClient.Users.Get(ctx, userName)
-	if err != nil { // TODO(mszostok): implement retry?
-		switch err := err.(type) {
-		case *github.ErrorResponse:
-			if err.Response.StatusCode == http.StatusNotFound {
-				return newValidateError("User %q does not have github account", name)
-			}
-			return newValidateError("HTTP error occurred while calling GitHub: %v", err).AsPermanent()
-		case *github.RateLimitError:
-			return newValidateError("GitHub rate limit reached: %v", err.Message).AsPermanent()
+	accountType, exists := org.users[userName]
+	if !exists {
+		return newValidateError("User %q does not have github account", name)
+	}
+
+	// organizations and bots cannot approve pull requests, so GitHub ignores them as code owners
+	switch accountType {
+	case github.OrganizationAccount:
+		return newValidateError("%q is an organization account and cannot review PRs, did you mean %s/<team>?", name, name)
+	case github.BotAccount:
+		return newValidateError("%q is a bot account and cannot review PRs, use a user or a team that reviews its changes instead.", name)
+	}
+
+	// personal repositories don't have members, their owners need to be collaborators
+	_, isMember := org.members[userName]
+	if !personal && !isMember && !v.allowOutsideCollabs {
+		return newValidateError("User %q is not a member of the organization", name)
+	}
+
+	// GitHub ignores code owners who cannot approve pull requests, even if they are organization members.
+	// Outside collaborators are valid owners only if they were granted write access to the repository.
+	perm, err := v.backend.UserRepoPermission(ctx, v.orgName, v.orgRepoName, userName)
+	if err != nil {
+		if apiStatusCode(err) == http.StatusUnauthorized {
+			return newValidateError(
+				"User permissions information for %q/%q could not be queried. Requires GitHub authorization.",
+				v.orgName, v.orgRepoName).AsUnverified()
+		}
+		return newValidateError("%s", describeAPIError(err)).AsUnverified()
+	}
+
+	if perm == github.PermissionNone {
+		switch {
+		case personal:
+			return newValidateError("User %q is not a collaborator of the repository %q.", name, v.orgRepoName)
+		case membersKnown && !isMember:
+			return newValidateError(
+				"User %q is neither a member of the organization nor a collaborator of the repository %q.",
+				name, v.orgRepoName)
 		default:
-			return newValidateError("Unknown error occurred while calling GitHub: %v", err).AsPermanent()
+			return newValidateError(
+				"User %q does not have permissions associated with the repository %q.",
+				name, v.orgRepoName)
 		}
 	}
 
-	_, isMember := (*v.orgMembers)[userName]
-	if !isMember {
-		return newValidateError("User %q is not a member of the organization", name)
+	if !perm.CanReview() {
+		return newValidateError(
+			"User %q cannot review PRs on %q as they have only %s permissions.",
+			name, v.orgRepoName, perm)
 	}
 
 	return nil
 }
 
-// There is a method to check if user is a org member
-//
-//	client.Organizations.IsMember(context.Background(), "org-name", "user-name")
-//
-// But latency is too huge for checking each single user independent
-// better and faster is to ask for all members and cache them.
-func (v *ValidOwner) initOrgListMembers(ctx context.Context) error {
-	opt := &github.ListMembersOptions{
-		ListOptions: github.ListOptions{PerPage: 100},
-	}
-
-	var allMembers []*github.User
-	for {
-		users, resp, err := v.ghClient.Organizations.ListMembers(ctx, v.orgName, opt)
-		if err != nil {
-			return err
-		}
-		allMembers = append(allMembers, users...)
-		if resp.NextPage == 0 {
-			break
-		}
-		opt.Page = resp.NextPage
+func (v *ValidOwner) initOrgListMembers(ctx context.Context, org *orgData) {
+	logins, err := v.backend.OrgMembers(ctx, v.orgName)
+	if err != nil {
+		org.membersErr = newValidateError("Cannot initialize organization member list: %v", err).AsUnverified()
+		return
 	}
 
-	v.orgMembers = &map[string]struct{}{}
-	for _, u := range allMembers {
-		(*v.orgMembers)[u.GetLogin()] = struct{}{}
+	org.members = map[string]struct{}{}
+	for _, login := range logins {
+		org.members[login] = struct{}{}
 	}
+}
 
-	return nil
+// initExistingUsers fetches all users referenced in the CODEOWNERS file at once,
+// so the backend can query them in batches.
+func (v *ValidOwner) initExistingUsers(ctx context.Context, org *orgData) {
+	users, err := v.backend.Users(ctx, org.referencedUsers)
+	if err != nil {
+		org.usersErr = newValidateError("%s", describeAPIError(err)).AsUnverified()
+		return
+	}
+
+	org.users = map[string]github.AccountType{}
+	for _, u := range users {
+		org.users[u.Login] = u.Type
+	}
+}
+
+// referencedUserLogins returns logins of all users which are validated in given entries.
+func (v *ValidOwner) referencedUserLogins(entries []codeowners.Entry) []string {
+	var (
+		logins []string
+		seen   = map[string]struct{}{}
+	)
+	for _, entry := range entries {
+		for _, ownerName := range entry.Owners {
+			if v.isIgnoredOwner(ownerName) || !isGitHubUser(ownerName) {
+				continue
+			}
+			login := strings.TrimPrefix(ownerName, "@")
+			if _, found := seen[login]; found {
+				continue
+			}
+			seen[login] = struct{}{}
+			logins = append(logins, login)
+		}
+	}
+	return logins
 }
 
 // Name returns human-readable name of the validator
@@ -360,16 +513,20 @@ func (ValidOwner) Name() string {
 
 // CheckSatisfied checks if this check has all requirements satisfied to be successfully executed.
 func (v *ValidOwner) CheckSatisfied(ctx context.Context) error {
-	_, resp, err := v.ghClient.Repositories.Get(ctx, v.orgName, v.orgRepoName)
+	scopes, err := v.backend.Scopes(ctx, v.orgName, v.orgRepoName)
 	if err != nil {
-		switch err := err.(type) {
-		case *github.ErrorResponse:
-			if err.Response.StatusCode == http.StatusNotFound {
-				return fmt.Errorf("repository %s/%s not found, or it's private and token doesn't have enough permission", v.orgName, v.orgRepoName)
-			}
+		var apiErr *github.APIError
+		switch {
+		case errors.Is(err, github.ErrNotInSnapshot):
+			return fmt.Errorf("outdated or incomplete snapshot: %v", err)
+		case !errors.As(err, &apiErr):
+			return fmt.Errorf("unknown error occurred while calling GitHub: %v", err)
+		case apiErr.RateLimited:
+			return fmt.Errorf("GitHub rate limit reached: %v", err)
+		case apiErr.StatusCode == http.StatusNotFound:
+			return fmt.Errorf("repository %s/%s not found, or it's private and token doesn't have enough permission", v.orgName, v.orgRepoName)
+		case apiErr.StatusCode != 0:
 			return fmt.Errorf("HTTP error occurred while calling GitHub: %v", err)
-		case *github.RateLimitError:
-			return fmt.Errorf("GitHub rate limit reached: %v", err.Message)
 		default:
 			return fmt.Errorf("unknown error occurred while calling GitHub: %v", err)
 		}
@@ -381,15 +538,18 @@ func (v *ValidOwner) CheckSatisfied(ctx context.Context) error {
 		return nil
 	}
 
-	return v.checkRequiredScopes(resp.Header)
+	// personal repositories have neither teams nor members, so the organization scope is not needed
+	if ownerType, err := v.backend.OwnerType(ctx, v.orgName, v.orgRepoName); err == nil && ownerType == github.UserAccount {
+		return nil
+	}
+
+	return v.checkRequiredScopes(scopes)
 }
 
-func (*ValidOwner) checkRequiredScopes(header http.Header) error {
-	gotScopes := strings.Split(header.Get(scopeHeader), ",")
-	presentScope := map[github.Scope]struct{}{}
+func (*ValidOwner) checkRequiredScopes(gotScopes []string) error {
+	presentScope := map[string]struct{}{}
 	for _, scope := range gotScopes {
-		scope = strings.TrimSpace(scope)
-		presentScope[github.Scope(scope)] = struct{}{}
+		presentScope[scope] = struct{}{}
 	}
 
 	var missing []string
@@ -397,7 +557,7 @@ func (*ValidOwner) checkRequiredScopes(header http.Header) error {
 		if _, found := presentScope[reqScope]; found {
 			continue
 		}
-		missing = append(missing, string(reqScope))
+		missing = append(missing, reqScope)
 	}
 
 	if len(missing) > 0 {
@@ -406,3 +566,27 @@ func (*ValidOwner) checkRequiredScopes(header http.Header) error {
 
 	return nil
 }
+
+// apiStatusCode returns the HTTP status code of a failed GitHub API call, 0 if it's unknown.
+func apiStatusCode(err error) int {
+	var apiErr *github.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// describeAPIError returns the issue message for a failed GitHub API call.
+func describeAPIError(err error) string {
+	var apiErr *github.APIError
+	switch {
+	case errors.Is(err, github.ErrNotInSnapshot):
+		return fmt.Sprintf("Outdated or incomplete snapshot: %v", err)
+	case errors.As(err, &apiErr) && apiErr.RateLimited:
+		return fmt.Sprintf("GitHub rate limit reached: %v", err)
+	case apiStatusCode(err) != 0:
+		return fmt.Sprintf("HTTP error occurred while calling GitHub: %v", err)
+	default:
+		return fmt.Sprintf("Unknown error occurred while calling GitHub: %v", err)
+	}
+}
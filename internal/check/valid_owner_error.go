@@ -3,8 +3,9 @@ package check
 import "fmt"
 
 type validateError struct {
-	msg       string
-	permanent bool
+	msg string
+	// unverified means that the owner could not be verified, e.g. because the GitHub API call failed.
+	unverified bool
 }
 
 func newValidateError(format string, a ...interface{}) *validateError {
@@ -13,7 +14,7 @@ func newValidateError(format string, a ...interface{}) *validateError {
 	}
 }
 
-func (err *validateError) AsPermanent() *validateError {
-	err.permanent = true
+func (err *validateError) AsUnverified() *validateError {
+	err.unverified = true
 	return err
 }
@@ -2,9 +2,16 @@ package check_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"go.szostok.io/codeowners-validator/internal/check"
+	"go.szostok.io/codeowners-validator/internal/github"
 
 	"github.com/stretchr/testify/require"
 
@@ -159,3 +166,495 @@ func TestValidOwnerCheckerOwnersMustBeTeams(t *testing.T) {
 		})
 	}
 }
+
+func TestValidOwnerCheckerBackend(t *testing.T) {
+	// given
+	backend := &fakeBackend{
+		teams:       []string{"devs", "readers", "outsiders"},
+		permissions: map[string]github.Permission{"devs": github.PermissionWrite, "readers": github.PermissionRead},
+		members:     []string{"member", "reader", "stranger"},
+		users:       []string{"member", "outsider", "reader", "stranger"},
+		userPermissions: map[string]github.Permission{
+			"member": github.PermissionMaintain,
+			"reader": github.PermissionTriage,
+		},
+	}
+	ownerCheck, err := check.NewValidOwner(check.ValidOwnerConfig{
+		Repository:           "org/repo",
+		AllowUnownedPatterns: true,
+	}, backend, true)
+	require.NoError(t, err)
+
+	givenCodeowners := `
+*           @org/devs @member
+/docs/      @org/readers @outsider
+/infra/     @org/outsiders @org/unknown @other/devs
+/scripts/   @ghost-user @member
+/tests/     @reader @stranger
+`
+
+	// when
+	out, err := ownerCheck.Check(context.Background(), LoadInput(givenCodeowners))
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []check.Issue{
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(3), Message: `Team "readers" cannot review PRs on "repo" as neither it nor any parent team has write permissions.`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(3), Message: `User "@outsider" is not a member of the organization`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(4), Message: `Team "outsiders" does not have permissions associated with the repository "repo".`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(4), Message: `Team "@org/unknown" does not exist in organization "org".`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(4), Message: `Team "@other/devs" does not belong to "org" organization.`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(5), Message: `User "@ghost-user" does not have github account`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(6), Message: `User "@reader" cannot review PRs on "repo" as they have only triage permissions.`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(6), Message: `User "@stranger" does not have permissions associated with the repository "repo".`},
+	}, out.Issues)
+	assert.Equal(t, [][]string{{"member", "outsider", "ghost-user", "reader", "stranger"}}, backend.usersCalls, "users should be fetched in a single call")
+}
+
+func TestValidOwnerCheckerOutsideCollaborators(t *testing.T) {
+	givenCodeowners := `
+*           @member
+/docs/      @collaborator
+/infra/     @reader @stranger
+`
+
+	tests := map[string]struct {
+		allowOutsideCollaborators bool
+		expIssues                 []check.Issue
+	}{
+		"Should accept outside collaborators with write access": {
+			allowOutsideCollaborators: true,
+			expIssues: []check.Issue{
+				{Severity: check.Error, LineNo: ptr.Uint64Ptr(4), Message: `User "@reader" cannot review PRs on "repo" as they have only pull permissions.`},
+				{Severity: check.Error, LineNo: ptr.Uint64Ptr(4), Message: `User "@stranger" is neither a member of the organization nor a collaborator of the repository "repo".`},
+			},
+		},
+		"Should report outside collaborators if they are forbidden": {
+			allowOutsideCollaborators: false,
+			expIssues: []check.Issue{
+				{Severity: check.Error, LineNo: ptr.Uint64Ptr(3), Message: `User "@collaborator" is not a member of the organization`},
+				{Severity: check.Error, LineNo: ptr.Uint64Ptr(4), Message: `User "@reader" is not a member of the organization`},
+				{Severity: check.Error, LineNo: ptr.Uint64Ptr(4), Message: `User "@stranger" is not a member of the organization`},
+			},
+		},
+	}
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			backend := &fakeBackend{
+				members: []string{"member"},
+				users:   []string{"member", "collaborator", "reader", "stranger"},
+				userPermissions: map[string]github.Permission{
+					"member":       github.PermissionWrite,
+					"collaborator": github.PermissionWrite,
+					"reader":       github.PermissionRead,
+				},
+			}
+			ownerCheck, err := check.NewValidOwner(check.ValidOwnerConfig{
+				Repository:                "org/repo",
+				AllowUnownedPatterns:      true,
+				AllowOutsideCollaborators: tc.allowOutsideCollaborators,
+			}, backend, true)
+			require.NoError(t, err)
+
+			// when
+			out, err := ownerCheck.Check(context.Background(), LoadInput(givenCodeowners))
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, tc.expIssues, out.Issues)
+		})
+	}
+}
+
+func TestValidOwnerCheckerSnapshotWithoutRepository(t *testing.T) {
+	// given
+	backend := github.NewOffline(&github.Snapshot{
+		Version: github.SnapshotVersion,
+		Org:     "org",
+		Members: []string{"member"},
+		Collaborators: map[string]map[string]github.Permission{
+			"other-repo": {"collaborator": github.PermissionWrite},
+		},
+	})
+	ownerCheck, err := check.NewValidOwner(check.ValidOwnerConfig{
+		Repository:                "org/repo",
+		AllowOutsideCollaborators: true,
+	}, backend, false)
+	require.NoError(t, err)
+
+	// when
+	out, err := ownerCheck.Check(context.Background(), LoadInput("* @member @collaborator"))
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []check.Issue{
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(1), Message: `Could not verify owner "@member": Outdated or incomplete snapshot: repository "repo" not present in snapshot`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(1), Message: `Could not verify owner "@collaborator": Outdated or incomplete snapshot: repository "repo" not present in snapshot`},
+	}, out.Issues)
+}
+
+func TestValidOwnerCheckerPersonalRepository(t *testing.T) {
+	// given
+	backend := &fakeBackend{
+		ownerType: github.UserAccount,
+		users:     []string{"owner", "collaborator", "reader", "stranger"},
+		userPermissions: map[string]github.Permission{
+			"owner":        github.PermissionAdmin,
+			"collaborator": github.PermissionWrite,
+			"reader":       github.PermissionRead,
+		},
+	}
+	ownerCheck, err := check.NewValidOwner(check.ValidOwnerConfig{
+		Repository:           "owner/repo",
+		AllowUnownedPatterns: true,
+	}, backend, true)
+	require.NoError(t, err)
+
+	givenCodeowners := `
+*           @owner @collaborator
+/docs/      @owner/docs
+/infra/     @reader @stranger @ghost-user
+`
+
+	// when
+	out, err := ownerCheck.Check(context.Background(), LoadInput(givenCodeowners))
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []check.Issue{
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(3), Message: `Team "@owner/docs" is not allowed as the repository owner/repo is owned by a personal account.`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(4), Message: `User "@reader" cannot review PRs on "repo" as they have only pull permissions.`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(4), Message: `User "@stranger" is not a collaborator of the repository "repo".`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(4), Message: `User "@ghost-user" does not have github account`},
+	}, out.Issues)
+}
+
+func TestValidOwnerCheckerAccountTypes(t *testing.T) {
+	// given
+	backend := &fakeBackend{
+		members: []string{"member", "org", "dependabot"},
+		users:   []string{"member", "org", "dependabot"},
+		accountTypes: map[string]github.AccountType{
+			"org":        github.OrganizationAccount,
+			"dependabot": github.BotAccount,
+		},
+		userPermissions: map[string]github.Permission{
+			"member":     github.PermissionWrite,
+			"org":        github.PermissionAdmin,
+			"dependabot": github.PermissionWrite,
+		},
+	}
+	ownerCheck, err := check.NewValidOwner(check.ValidOwnerConfig{Repository: "org/repo"}, backend, true)
+	require.NoError(t, err)
+
+	// when
+	out, err := ownerCheck.Check(context.Background(), LoadInput("* @member @org @dependabot"))
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []check.Issue{
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(1), Message: `"@org" is an organization account and cannot review PRs, did you mean @org/<team>?`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(1), Message: `"@dependabot" is a bot account and cannot review PRs, use a user or a team that reviews its changes instead.`},
+	}, out.Issues)
+}
+
+func TestValidOwnerCheckerConcurrency(t *testing.T) {
+	// given
+	backend := &fakeBackend{
+		permissions: map[string]github.Permission{},
+		delay:       10 * time.Millisecond,
+	}
+	var (
+		givenCodeowners strings.Builder
+		expIssues       []check.Issue
+	)
+	for i := 1; i <= 40; i++ {
+		team := fmt.Sprintf("team-%d", i)
+		backend.teams = append(backend.teams, team)
+		fmt.Fprintf(&givenCodeowners, "/dir-%d/ @org/%s @org/team-1\n", i, team)
+
+		// every third team has write permission, others can only read
+		if i%3 == 0 {
+			backend.permissions[team] = github.PermissionWrite
+			continue
+		}
+		backend.permissions[team] = github.PermissionRead
+		expIssues = append(expIssues, check.Issue{
+			Severity: check.Error,
+			LineNo:   ptr.Uint64Ptr(uint64(i)),
+			Message:  fmt.Sprintf(`Team %q cannot review PRs on "repo" as neither it nor any parent team has write permissions.`, team),
+		})
+	}
+
+	ownerCheck, err := check.NewValidOwner(check.ValidOwnerConfig{
+		Repository:  "org/repo",
+		Concurrency: 4,
+	}, backend, true)
+	require.NoError(t, err)
+
+	// when
+	out, err := ownerCheck.Check(context.Background(), LoadInput(givenCodeowners.String()))
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, expIssues, out.Issues)
+	assert.Equal(t, 4, backend.maxInFlight)
+}
+
+func TestValidOwnerCheckerCanceledDuringValidation(t *testing.T) {
+	// given
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	backend := &fakeBackend{
+		permissions: map[string]github.Permission{},
+		// the first permission lookup cancels the context, so no other owners should be validated
+		onTeamPermission: cancel,
+	}
+	var givenCodeowners strings.Builder
+	for i := 1; i <= 40; i++ {
+		team := fmt.Sprintf("team-%d", i)
+		backend.teams = append(backend.teams, team)
+		backend.permissions[team] = github.PermissionWrite
+		fmt.Fprintf(&givenCodeowners, "/dir-%d/ @org/%s\n", i, team)
+	}
+
+	ownerCheck, err := check.NewValidOwner(check.ValidOwnerConfig{
+		Repository:  "org/repo",
+		Concurrency: 1,
+	}, backend, true)
+	require.NoError(t, err)
+
+	// when
+	out, err := ownerCheck.Check(ctx, LoadInput(givenCodeowners.String()))
+
+	// then
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, out.Issues)
+	assert.Equal(t, 1, backend.teamPermissionCalls)
+}
+
+func TestValidOwnerCheckerConcurrentChecks(t *testing.T) {
+	// given
+	backend := &fakeBackend{
+		members: []string{"alice", "bob"},
+		users:   []string{"alice", "bob"},
+		userPermissions: map[string]github.Permission{
+			"alice": github.PermissionWrite,
+			"bob":   github.PermissionWrite,
+		},
+	}
+	ownerCheck, err := check.NewValidOwner(check.ValidOwnerConfig{Repository: "org/repo"}, backend, true)
+	require.NoError(t, err)
+
+	// each check references different users, so they fail if the fetched users are shared between checks
+	inputs := []string{"* @alice", "* @bob"}
+
+	// when
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(in string) {
+			defer wg.Done()
+			out, err := ownerCheck.Check(context.Background(), LoadInput(in))
+
+			// then
+			assert.NoError(t, err)
+			assert.Empty(t, out.Issues, in)
+		}(inputs[i%len(inputs)])
+	}
+	wg.Wait()
+}
+
+func TestValidOwnerCheckerBackendFailure(t *testing.T) {
+	// given
+	backend := &fakeBackend{
+		err: &github.APIError{StatusCode: http.StatusUnauthorized, Err: errors.New("401 Unauthorized")},
+	}
+	ownerCheck, err := check.NewValidOwner(check.ValidOwnerConfig{Repository: "org/repo"}, backend, true)
+	require.NoError(t, err)
+
+	// when
+	out, err := ownerCheck.Check(context.Background(), LoadInput("* @org/devs @org/admins"))
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []check.Issue{
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(1), Message: `Could not verify owner "@org/devs": Teams for organization "org" could not be queried. Requires GitHub authorization.`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(1), Message: `Could not verify owner "@org/admins": Teams for organization "org" could not be queried. Requires GitHub authorization.`},
+	}, out.Issues)
+}
+
+func TestValidOwnerCheckerPartialBackendFailure(t *testing.T) {
+	// given
+	backend := &fakeBackend{
+		teamsErr: &github.APIError{StatusCode: http.StatusInternalServerError, Err: errors.New("500 Internal Server Error")},
+		members:  []string{"alice"},
+		users:    []string{"alice", "bob"},
+		userPermissions: map[string]github.Permission{
+			"alice": github.PermissionWrite,
+		},
+	}
+	ownerCheck, err := check.NewValidOwner(check.ValidOwnerConfig{
+		Repository:         "org/repo",
+		UnverifiedSeverity: check.Warning,
+	}, backend, true)
+	require.NoError(t, err)
+
+	givenCodeowners := `* @org/devs @alice
+		/docs @bob @ghost`
+
+	// when
+	out, err := ownerCheck.Check(context.Background(), LoadInput(givenCodeowners))
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []check.Issue{
+		{Severity: check.Warning, LineNo: ptr.Uint64Ptr(1), Message: `Could not verify owner "@org/devs": HTTP error occurred while calling GitHub: 500 Internal Server Error`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(2), Message: `User "@bob" is not a member of the organization`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(2), Message: `User "@ghost" does not have github account`},
+	}, out.Issues)
+}
+
+func TestValidOwnerCheckerOrganizationLookupsFailure(t *testing.T) {
+	// given
+	serverErr := &github.APIError{StatusCode: http.StatusInternalServerError, Err: errors.New("500 Internal Server Error")}
+	backend := &fakeBackend{
+		ownerTypeErr: serverErr,
+		membersErr:   serverErr,
+		teams:        []string{"devs"},
+		permissions: map[string]github.Permission{
+			"devs": github.PermissionWrite,
+		},
+		users: []string{"alice", "bob"},
+		userPermissions: map[string]github.Permission{
+			"alice": github.PermissionWrite,
+		},
+	}
+	allowOutsideCollabs := func(allow bool) *check.ValidOwner {
+		ownerCheck, err := check.NewValidOwner(check.ValidOwnerConfig{
+			Repository:                "org/repo",
+			AllowOutsideCollaborators: allow,
+		}, backend, true)
+		require.NoError(t, err)
+		return ownerCheck
+	}
+	givenCodeowners := "* @org/devs @alice @bob"
+
+	// when
+	allowed, err := allowOutsideCollabs(true).Check(context.Background(), LoadInput(givenCodeowners))
+	require.NoError(t, err)
+	forbidden, err := allowOutsideCollabs(false).Check(context.Background(), LoadInput(givenCodeowners))
+	require.NoError(t, err)
+
+	// then
+	assert.Equal(t, []check.Issue{
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(1), Message: `User "@bob" does not have permissions associated with the repository "repo".`},
+	}, allowed.Issues, "permissions should decide when the membership is not required")
+	assert.Equal(t, []check.Issue{
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(1), Message: `Could not verify owner "@alice": Cannot initialize organization member list: 500 Internal Server Error`},
+		{Severity: check.Error, LineNo: ptr.Uint64Ptr(1), Message: `Could not verify owner "@bob": Cannot initialize organization member list: 500 Internal Server Error`},
+	}, forbidden.Issues)
+}
+
+type fakeBackend struct {
+	// ownerType is the type of the repository owner, the organization is used if it's empty
+	ownerType   github.AccountType
+	teams       []string
+	permissions map[string]github.Permission
+	members     []string
+	users       []string
+	// accountTypes holds types of accounts which are not users, indexed by login
+	accountTypes map[string]github.AccountType
+	// userPermissions holds repository permissions of users, indexed by login
+	userPermissions map[string]github.Permission
+	err             error
+	teamsErr        error
+	ownerTypeErr    error
+	membersErr      error
+	delay           time.Duration
+	// onTeamPermission is called on each team permission lookup
+	onTeamPermission func()
+
+	mu                  sync.Mutex
+	usersCalls          [][]string
+	inFlight            int
+	maxInFlight         int
+	teamPermissionCalls int
+}
+
+func (f *fakeBackend) Scopes(context.Context, string, string) ([]string, error) {
+	return []string{"read:org"}, f.err
+}
+
+func (f *fakeBackend) OwnerType(context.Context, string, string) (github.AccountType, error) {
+	if f.ownerTypeErr != nil {
+		return "", f.ownerTypeErr
+	}
+	if f.ownerType == "" {
+		return github.OrganizationAccount, nil
+	}
+	return f.ownerType, nil
+}
+
+func (f *fakeBackend) Teams(context.Context, string) ([]string, error) {
+	if f.teamsErr != nil {
+		return nil, f.teamsErr
+	}
+	return f.teams, f.err
+}
+
+func (f *fakeBackend) TeamRepoPermission(_ context.Context, _, slug, _ string) (github.Permission, error) {
+	f.mu.Lock()
+	f.inFlight++
+	f.teamPermissionCalls++
+	if f.inFlight > f.maxInFlight {
+		f.maxInFlight = f.inFlight
+	}
+	f.mu.Unlock()
+
+	if f.onTeamPermission != nil {
+		f.onTeamPermission()
+	}
+
+	time.Sleep(f.delay)
+
+	f.mu.Lock()
+	f.inFlight--
+	f.mu.Unlock()
+
+	return f.permissions[slug], f.err
+}
+
+func (f *fakeBackend) UserRepoPermission(_ context.Context, _, _, login string) (github.Permission, error) {
+	return f.userPermissions[login], f.err
+}
+
+func (f *fakeBackend) OrgMembers(context.Context, string) ([]string, error) {
+	if f.membersErr != nil {
+		return nil, f.membersErr
+	}
+	return f.members, f.err
+}
+
+func (f *fakeBackend) Users(_ context.Context, logins []string) ([]github.User, error) {
+	f.mu.Lock()
+	f.usersCalls = append(f.usersCalls, logins)
+	f.mu.Unlock()
+
+	var out []github.User
+	for _, l := range logins {
+		for _, u := range f.users {
+			if l != u {
+				continue
+			}
+			accountType, found := f.accountTypes[l]
+			if !found {
+				accountType = github.UserAccount
+			}
+			out = append(out, github.User{Login: l, Type: accountType})
+		}
+	}
+	return out, f.err
+}
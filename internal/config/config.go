@@ -0,0 +1,89 @@
+// Package config loads the application configuration from multiple sources.
+//
+// Each option can be set with a CLI flag, an environment variable or the configuration file. The precedence is:
+//
+//	flags > environment variables > configuration file > defaults
+//
+// Options are described by Go structs using the same `envconfig` tags as the `envconfig` library,
+// e.g. `OwnerChecker.IgnoredOwners` can be set via the `--owner-checker-ignored-owners` flag
+// or the `OWNER_CHECKER_IGNORED_OWNERS` environment variable.
+package config
+
+import (
+	"fmt"
+)
+
+// Loader loads configuration from the given sources.
+type Loader struct {
+	sources []Source
+}
+
+// NewLoader returns a new Loader instance. Sources are ordered by priority,
+// the value from the first source that has it set wins.
+func NewLoader(sources ...Source) *Loader {
+	return &Loader{sources: sources}
+}
+
+// DefaultSourceName is the source name of options which were not set by a user.
+const DefaultSourceName = "default"
+
+// Value holds the raw effective value of an option together with the name of its source.
+type Value struct {
+	Field  Field
+	Raw    string
+	Source string
+}
+
+// Load initializes a given configuration struct pointer.
+func (l *Loader) Load(conf interface{}) error {
+	for _, v := range l.Describe(conf) {
+		if v.Source == "" {
+			if v.Field.Optional {
+				continue
+			}
+			return l.missingErr(v.Field)
+		}
+
+		if err := setField(v.Field, v.Raw, v.Source == DefaultSourceName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Describe returns raw effective values of all options defined by a given configuration struct.
+// The source is empty if the option is not set at all.
+func (l *Loader) Describe(conf interface{}) []Value {
+	var out []Value
+	for _, f := range Fields(conf) {
+		out = append(out, l.lookup(f))
+	}
+	return out
+}
+
+func (l *Loader) lookup(f Field) Value {
+	for _, s := range l.sources {
+		if raw, found := s.Lookup(f); found {
+			name := s.Name()
+			if n, ok := s.(fieldNamer); ok {
+				name = n.NameOf(f)
+			}
+			return Value{Field: f, Raw: raw, Source: name}
+		}
+	}
+	if f.Default != "" {
+		return Value{Field: f, Raw: f.Default, Source: DefaultSourceName}
+	}
+	return Value{Field: f}
+}
+
+func (l *Loader) missingErr(f Field) error {
+	envName := f.EnvName()
+	for _, s := range l.sources {
+		if env, ok := s.(*EnvSource); ok {
+			envName = env.EnvName(f)
+		}
+	}
+	return fmt.Errorf("missing required configuration %s: use the --%s flag or the %s environment variable", f.Key(), f.FlagName(), envName)
+}
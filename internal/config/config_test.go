@@ -0,0 +1,168 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"go.szostok.io/codeowners-validator/internal/check"
+	"go.szostok.io/codeowners-validator/internal/config"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	RepositoryPath string
+	FailureLevel   check.SeverityType `envconfig:"default=warning"`
+	Owners         []string           `envconfig:"default=@ghost;@octocat"`
+	Github         struct {
+		HTTPRequestTimeout time.Duration `envconfig:"default=30s"`
+		AppID              int64         `envconfig:"optional"`
+	}
+}
+
+func TestLoaderPrecedence(t *testing.T) {
+	t.Run("Should use defaults when option is not set", func(t *testing.T) {
+		// given
+		t.Setenv("REPOSITORY_PATH", "./repo")
+		loader := config.NewLoader(config.NewEnvSource())
+
+		// when
+		var cfg testConfig
+		err := loader.Load(&cfg)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "./repo", cfg.RepositoryPath)
+		assert.Equal(t, check.Warning, cfg.FailureLevel)
+		assert.Equal(t, []string{"@ghost", "@octocat"}, cfg.Owners)
+		assert.Equal(t, 30*time.Second, cfg.Github.HTTPRequestTimeout)
+		assert.Zero(t, cfg.Github.AppID)
+	})
+
+	t.Run("Should prefer flags over env variables", func(t *testing.T) {
+		// given
+		t.Setenv("REPOSITORY_PATH", "./from-env")
+		t.Setenv("FAILURE_LEVEL", "error")
+		t.Setenv("GITHUB_APP_ID", "42")
+
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		config.RegisterFlags(flags, &testConfig{})
+		require.NoError(t, flags.Parse([]string{"--repository-path=./from-flag", "--owners=@a,@b", "--github-http-request-timeout=1m"}))
+
+		loader := config.NewLoader(config.NewFlagSource(flags), config.NewEnvSource())
+
+		// when
+		var cfg testConfig
+		err := loader.Load(&cfg)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "./from-flag", cfg.RepositoryPath)
+		assert.Equal(t, check.Error, cfg.FailureLevel)
+		assert.Equal(t, []string{"@a", "@b"}, cfg.Owners)
+		assert.Equal(t, time.Minute, cfg.Github.HTTPRequestTimeout)
+		assert.EqualValues(t, 42, cfg.Github.AppID)
+	})
+
+	t.Run("Should read env variables with prefix", func(t *testing.T) {
+		// given
+		t.Setenv("ENVS_PREFIX", "INPUT")
+		t.Setenv("INPUT_REPOSITORY_PATH", "./repo")
+		t.Setenv("INPUT_OWNERS", "@a, @b")
+
+		loader := config.NewLoader(config.NewEnvSource())
+
+		// when
+		var cfg testConfig
+		err := loader.Load(&cfg)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "./repo", cfg.RepositoryPath)
+		assert.Equal(t, []string{"@a", "@b"}, cfg.Owners)
+	})
+}
+
+func TestLoaderDescribe(t *testing.T) {
+	// given
+	type secretConfig struct {
+		RepositoryPath string
+		Owners         []string `envconfig:"default=@ghost;@octocat"`
+		AccessToken    string   `envconfig:"optional" secret:"true"`
+		AppID          int64    `envconfig:"optional"`
+	}
+	t.Setenv("ACCESS_TOKEN", "token")
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	config.RegisterFlags(flags, &secretConfig{})
+	require.NoError(t, flags.Parse([]string{"--repository-path=./repo"}))
+
+	loader := config.NewLoader(config.NewFlagSource(flags), config.NewEnvSource())
+
+	// when
+	values := loader.Describe(&secretConfig{})
+
+	// then
+	type got struct {
+		Key, Raw, Source string
+		Secret           bool
+	}
+	var gotValues []got
+	for _, v := range values {
+		gotValues = append(gotValues, got{Key: v.Field.Key(), Raw: v.Raw, Source: v.Source, Secret: v.Field.Secret})
+	}
+	assert.Equal(t, []got{
+		{Key: "RepositoryPath", Raw: "./repo", Source: "flag"},
+		{Key: "Owners", Raw: "@ghost;@octocat", Source: config.DefaultSourceName},
+		{Key: "AccessToken", Raw: "token", Source: "env", Secret: true},
+		{Key: "AppID"},
+	}, gotValues)
+}
+
+func TestLoaderFailures(t *testing.T) {
+	t.Run("Should report missing required option", func(t *testing.T) {
+		// given
+		t.Setenv("ENVS_PREFIX", "INPUT")
+		loader := config.NewLoader(config.NewEnvSource())
+
+		// when
+		var cfg testConfig
+		err := loader.Load(&cfg)
+
+		// then
+		assert.EqualError(t, err, "missing required configuration RepositoryPath: use the --repository-path flag or the INPUT_REPOSITORY_PATH environment variable")
+	})
+
+	t.Run("Should report malformed value", func(t *testing.T) {
+		// given
+		t.Setenv("REPOSITORY_PATH", "./repo")
+		t.Setenv("FAILURE_LEVEL", "fatal")
+		loader := config.NewLoader(config.NewEnvSource())
+
+		// when
+		var cfg testConfig
+		err := loader.Load(&cfg)
+
+		// then
+		assert.EqualError(t, err, `while parsing value "fatal" for FailureLevel: not a valid severity type: "fatal"`)
+	})
+}
+
+func TestFieldNames(t *testing.T) {
+	fields := config.Fields(&testConfig{})
+
+	var got [][3]string
+	for _, f := range fields {
+		got = append(got, [3]string{f.EnvName(), f.FlagName(), f.FileKey()})
+	}
+
+	assert.Equal(t, [][3]string{
+		{"REPOSITORY_PATH", "repository-path", "repositoryPath"},
+		{"FAILURE_LEVEL", "failure-level", "failureLevel"},
+		{"OWNERS", "owners", "owners"},
+		{"GITHUB_HTTP_REQUEST_TIMEOUT", "github-http-request-timeout", "github.httpRequestTimeout"},
+		{"GITHUB_APP_ID", "github-app-id", "github.appID"},
+	}, got)
+}
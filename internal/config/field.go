@@ -0,0 +1,153 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Field describes a single configuration option.
+type Field struct {
+	// Path holds the names of the Go struct fields leading to the option, e.g. [OwnerChecker IgnoredOwners].
+	Path []string
+	// Type is the Go type of the option.
+	Type reflect.Type
+	// Default holds the raw default value taken from the `envconfig:"default=..."` tag.
+	Default string
+	// Optional is true when the option doesn't need to be set.
+	Optional bool
+	// Description is a human-readable description taken from the `desc` tag.
+	Description string
+	// Secret is true when the value must not be printed. Taken from the `secret:"true"` tag.
+	Secret bool
+	// Sensitive is true when the option can be set only via flags and environment variables, as the
+	// configuration file is controlled by anyone who can open a pull request. Taken from the `sensitive:"true"` tag.
+	Sensitive bool
+
+	value reflect.Value
+}
+
+// EnvName returns the environment variable name for a given field, e.g. OWNER_CHECKER_IGNORED_OWNERS.
+func (f Field) EnvName() string {
+	return strings.ToUpper(strings.Join(f.words(), "_"))
+}
+
+// FlagName returns the CLI flag name for a given field, e.g. owner-checker-ignored-owners.
+func (f Field) FlagName() string {
+	return strings.ToLower(strings.Join(f.words(), "-"))
+}
+
+// FileKey returns the configuration file key for a given field, e.g. ownerChecker.ignoredOwners.
+func (f Field) FileKey() string {
+	keys := make([]string, 0, len(f.Path))
+	for _, p := range f.Path {
+		words := splitCamelCase(p)
+		words[0] = strings.ToLower(words[0])
+		keys = append(keys, strings.Join(words, ""))
+	}
+	return strings.Join(keys, ".")
+}
+
+// Key returns the dot separated Go path of a given field, e.g. OwnerChecker.IgnoredOwners.
+func (f Field) Key() string {
+	return strings.Join(f.Path, ".")
+}
+
+// IsSlice returns true if the field holds a list of scalar values.
+func (f Field) IsSlice() bool {
+	return f.Type.Kind() == reflect.Slice && !isUnmarshaler(f.Type)
+}
+
+func (f Field) words() []string {
+	var out []string
+	for _, p := range f.Path {
+		out = append(out, splitCamelCase(p)...)
+	}
+	return out
+}
+
+// Fields returns all configuration options defined by a given struct pointer.
+// It follows the `envconfig` tags conventions, so the same structs can be used.
+func Fields(conf interface{}) []Field {
+	v := reflect.ValueOf(conf)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	return walk(v, nil, false)
+}
+
+func walk(v reflect.Value, parent []string, optional bool) []Field {
+	var out []Field
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag := parseTag(sf.Tag.Get("envconfig"))
+		if tag.skip {
+			continue
+		}
+
+		path := append(append([]string{}, parent...), sf.Name)
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct && !isUnmarshaler(field.Type()) {
+			out = append(out, walk(field, path, optional || tag.optional)...)
+			continue
+		}
+
+		out = append(out, Field{
+			Path:        path,
+			Type:        field.Type(),
+			Default:     tag.defaultVal,
+			Optional:    optional || tag.optional,
+			Description: sf.Tag.Get("desc"),
+			Secret:      sf.Tag.Get("secret") == "true",
+			Sensitive:   sf.Tag.Get("sensitive") == "true",
+			value:       field,
+		})
+	}
+	return out
+}
+
+type tag struct {
+	optional   bool
+	skip       bool
+	defaultVal string
+}
+
+func parseTag(s string) tag {
+	var t tag
+	for _, v := range strings.Split(s, ",") {
+		switch {
+		case v == "-":
+			t.skip = true
+		case v == "optional":
+			t.optional = true
+		case strings.HasPrefix(v, "default="):
+			t.defaultVal = strings.TrimPrefix(v, "default=")
+		}
+	}
+	return t
+}
+
+// splitCamelCase splits Go identifier into words, e.g. HTTPRequestTimeout into [HTTP Request Timeout].
+func splitCamelCase(s string) []string {
+	var (
+		words []string
+		runes = []rune(s)
+		start = 0
+	)
+	for i := 1; i < len(runes); i++ {
+		if !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		prevLower := unicode.IsLower(runes[i-1])
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if prevLower || nextLower {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
@@ -0,0 +1,357 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames holds the names of the configuration file which is discovered in the repository root.
+var FileNames = []string{".codeowners-validator.yaml", ".codeowners-validator.yml"}
+
+// FileSource reads configuration from a YAML file.
+//
+// Keys are the lower camel case Go field names, e.g. `OwnerChecker.IgnoredOwners` is set via:
+//
+//	ownerChecker:
+//	  ignoredOwners: ["@ghost"]
+type FileSource struct {
+	path   string
+	values map[string]interface{}
+	// origins holds the name of the file which set a given key, indexed by lowercase dot separated key.
+	origins map[string]string
+}
+
+// NewFileSource returns a new FileSource instance for a given file.
+//
+// The file may inherit values from other files of a given repository listed under the `extends` key.
+// The paths are relative to the repository root, and files outside the repository are rejected.
+// If the root is empty, the directory of the given file is used.
+// Base files are merged in the listed order and the extending file is applied on top of them.
+// Nested keys are merged, while scalar values and lists are replaced.
+func NewFileSource(path, root string) (*FileSource, error) {
+	if root == "" {
+		root = filepath.Dir(path)
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+
+	origins := map[string]string{}
+	values, err := readFile(path, path, root, nil, origins)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSource{path: path, values: values, origins: origins}, nil
+}
+
+// readFile reads a given file together with all files it extends. The name of the file which set
+// a given key is stored in origins, so values inherited from the extended files can be attributed.
+// The chain holds already visited files to detect cycles.
+func readFile(path, name, root string, chain []string, origins map[string]string) (map[string]interface{}, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, visited := range chain {
+		if visited == abs {
+			return nil, fmt.Errorf("config file %s is extended in a cycle: %s", path, strings.Join(append(chain, abs), " -> "))
+		}
+	}
+	chain = append(chain, abs)
+
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "while reading config file")
+	}
+
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(raw, &values); err != nil {
+		return nil, parseError(path, err)
+	}
+
+	bases, err := extendsList(path, values)
+	if err != nil {
+		return nil, err
+	}
+	delete(values, extendsKey)
+
+	merged := map[string]interface{}{}
+	for _, base := range bases {
+		base, err := resolveExtended(path, root, base)
+		if err != nil {
+			return nil, err
+		}
+		baseName := base
+		if rel, err := filepath.Rel(root, base); err == nil {
+			baseName = rel
+		}
+		baseValues, err := readFile(base, baseName, root, chain, origins)
+		if err != nil {
+			return nil, errors.Wrapf(err, "while extending %s", path)
+		}
+		merged = merge(merged, baseValues)
+	}
+
+	recordOrigins(origins, "", values, name)
+	return merge(merged, values), nil
+}
+
+// recordOrigins stores a given file name for all keys of given values, including the nested ones.
+func recordOrigins(origins map[string]string, prefix string, values map[string]interface{}, name string) {
+	for k, v := range values {
+		key := strings.ToLower(k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		origins[key] = name
+		if nested, ok := v.(map[string]interface{}); ok {
+			recordOrigins(origins, key, nested, name)
+		}
+	}
+}
+
+const extendsKey = "extends"
+
+// yamlErrLine matches the line number reported by the YAML parser.
+var yamlErrLine = regexp.MustCompile(`line (\d+)`)
+
+// parseError describes a malformed file without its content, which YAML errors quote.
+// It protects from revealing content of files which are not configuration files.
+func parseError(path string, err error) error {
+	if m := yamlErrLine.FindStringSubmatch(err.Error()); m != nil {
+		return fmt.Errorf("config file %s is not a valid YAML mapping (line %s)", path, m[1])
+	}
+	return fmt.Errorf("config file %s is not a valid YAML mapping", path)
+}
+
+// resolveExtended returns the path of an extended file, which is relative to the repository root.
+// Symbolic links are resolved, so they cannot point outside the repository either.
+func resolveExtended(path, root, base string) (string, error) {
+	outside := fmt.Errorf("config file %s: only files from the repository can be extended, got %q", path, base)
+	if filepath.IsAbs(base) {
+		return "", outside
+	}
+
+	resolved := filepath.Join(root, base)
+	if evaluated, err := filepath.EvalSymlinks(resolved); err == nil {
+		resolved = evaluated
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", outside
+	}
+	return resolved, nil
+}
+
+func extendsList(path string, values map[string]interface{}) ([]string, error) {
+	var bases []string
+	switch v := values[extendsKey].(type) {
+	case nil:
+	case string:
+		bases = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("config file %s: %s must be a list of file paths", path, extendsKey)
+			}
+			bases = append(bases, s)
+		}
+	default:
+		return nil, fmt.Errorf("config file %s: %s must be a file path or a list of file paths", path, extendsKey)
+	}
+
+	for _, b := range bases {
+		if strings.Contains(b, "://") {
+			return nil, fmt.Errorf("config file %s: only local files can be extended, got %q", path, b)
+		}
+	}
+	return bases, nil
+}
+
+// merge deeply merges the overrides into the base. Keys are matched case-insensitively.
+func merge(base, overrides map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(base)+len(overrides))
+	for k, v := range base {
+		out[k] = v
+	}
+
+	for k, v := range overrides {
+		existingKey := k
+		for bk := range out {
+			if strings.EqualFold(bk, k) {
+				existingKey = bk
+			}
+		}
+
+		baseMap, baseIsMap := out[existingKey].(map[string]interface{})
+		overrideMap, overrideIsMap := v.(map[string]interface{})
+		delete(out, existingKey)
+		if baseIsMap && overrideIsMap {
+			out[k] = merge(baseMap, overrideMap)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// DiscoverFile returns the path to the configuration file stored in a given repository root.
+// Returns an empty path if there is no such file.
+func DiscoverFile(repoPath string) (string, error) {
+	for _, name := range FileNames {
+		p := filepath.Join(repoPath, name)
+		_, err := os.Stat(p)
+		switch {
+		case err == nil:
+			return p, nil
+		case os.IsNotExist(err):
+			continue
+		default:
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// Name returns human-readable name of the source.
+func (s *FileSource) Name() string {
+	return fmt.Sprintf("file (%s)", s.path)
+}
+
+// NameOf returns human-readable name of the file which set a given field.
+// It differs from Name if the value is inherited from an extended file.
+func (s *FileSource) NameOf(f Field) string {
+	if origin, found := s.origins[strings.ToLower(f.Key())]; found {
+		return fmt.Sprintf("file (%s)", origin)
+	}
+	return s.Name()
+}
+
+// Lookup returns the file value for a given field.
+func (s *FileSource) Lookup(f Field) (string, bool) {
+	var current interface{} = s.values
+	for _, p := range f.Path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		current, ok = lookupKey(m, p)
+		if !ok {
+			return "", false
+		}
+	}
+
+	if current == nil {
+		return "", false
+	}
+	return toRaw(f, current)
+}
+
+// Validate returns an error if the file contains keys which do not match any option
+// of the given configuration structs. It protects from silently ignored typos.
+// It also rejects sensitive options, also when they come from the extended files.
+func (s *FileSource) Validate(confs ...interface{}) error {
+	known := map[string]struct{}{}
+	sensitive := map[string]struct{}{}
+	for _, conf := range confs {
+		for _, f := range Fields(conf) {
+			for i := range f.Path {
+				known[strings.ToLower(strings.Join(f.Path[:i+1], "."))] = struct{}{}
+			}
+			if f.Sensitive {
+				sensitive[strings.ToLower(f.Key())] = struct{}{}
+			}
+		}
+	}
+
+	var unknown, denied []string
+	var walk func(prefix string, m map[string]interface{})
+	walk = func(prefix string, m map[string]interface{}) {
+		for k, v := range m {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			if _, found := known[strings.ToLower(key)]; !found {
+				unknown = append(unknown, key)
+				continue
+			}
+			if _, found := sensitive[strings.ToLower(key)]; found {
+				denied = append(denied, key)
+				continue
+			}
+			if nested, ok := v.(map[string]interface{}); ok && s.isStruct(key, confs) {
+				walk(key, nested)
+			}
+		}
+	}
+	walk("", s.values)
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown keys in config file %s: %s", s.path, strings.Join(unknown, ", "))
+	}
+	if len(denied) > 0 {
+		sort.Strings(denied)
+		return fmt.Errorf("keys in config file %s can be set only via flags or environment variables: %s", s.path, strings.Join(denied, ", "))
+	}
+	return nil
+}
+
+// isStruct returns true if a given key points to a group of options and not to a single option.
+func (*FileSource) isStruct(key string, confs []interface{}) bool {
+	for _, conf := range confs {
+		for _, f := range Fields(conf) {
+			if strings.EqualFold(f.Key(), key) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func lookupKey(m map[string]interface{}, name string) (interface{}, bool) {
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// toRaw converts YAML value into the raw representation understood by the Loader.
+func toRaw(f Field, v interface{}) (string, bool) {
+	if f.IsSlice() && isScalar(f.Type.Elem()) {
+		if list, ok := v.([]interface{}); ok {
+			items := make([]string, 0, len(list))
+			for _, item := range list {
+				items = append(items, fmt.Sprint(item))
+			}
+			return strings.Join(items, sliceSeparator), true
+		}
+	}
+
+	if isScalar(f.Type) {
+		return fmt.Sprint(v), true
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return string(out), true
+}
@@ -0,0 +1,270 @@
+package config_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.szostok.io/codeowners-validator/internal/check"
+	"go.szostok.io/codeowners-validator/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSource(t *testing.T) {
+	t.Run("Should read values from the config file", func(t *testing.T) {
+		// given
+		path := writeConfigFile(t, t.TempDir(), `
+repositoryPath: ./repo
+failureLevel: error
+owners: ["@a", "@b"]
+github:
+  httpRequestTimeout: 1m
+`)
+		fileSrc, err := config.NewFileSource(path, "")
+		require.NoError(t, err)
+
+		// when
+		var cfg testConfig
+		err = config.NewLoader(fileSrc).Load(&cfg)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "./repo", cfg.RepositoryPath)
+		assert.Equal(t, check.Error, cfg.FailureLevel)
+		assert.Equal(t, []string{"@a", "@b"}, cfg.Owners)
+		assert.Equal(t, time.Minute, cfg.Github.HTTPRequestTimeout)
+	})
+
+	t.Run("Should prefer env variables over the config file", func(t *testing.T) {
+		// given
+		t.Setenv("FAILURE_LEVEL", "warning")
+		path := writeConfigFile(t, t.TempDir(), `
+repositoryPath: ./repo
+failureLevel: error
+`)
+		fileSrc, err := config.NewFileSource(path, "")
+		require.NoError(t, err)
+
+		// when
+		var cfg testConfig
+		err = config.NewLoader(config.NewEnvSource(), fileSrc).Load(&cfg)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, check.Warning, cfg.FailureLevel)
+	})
+
+	t.Run("Should report unknown keys", func(t *testing.T) {
+		// given
+		path := writeConfigFile(t, t.TempDir(), `
+repositoryPath: ./repo
+failureLvl: error
+github:
+  appId: 42
+  token: abc
+`)
+		fileSrc, err := config.NewFileSource(path, "")
+		require.NoError(t, err)
+
+		// when
+		err = fileSrc.Validate(&testConfig{})
+
+		// then
+		assert.EqualError(t, err, "unknown keys in config file "+path+": failureLvl, github.token")
+	})
+}
+
+func TestFileSourceExtends(t *testing.T) {
+	t.Run("Should merge extended files in order", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "policy", "org.yaml"), `
+failureLevel: error
+owners: ["@org-bot"]
+github:
+  httpRequestTimeout: 1m
+  appID: 1
+`)
+		writeFile(t, filepath.Join(dir, "policy", "team.yaml"), `
+github:
+  appID: 2
+`)
+		path := writeConfigFile(t, dir, `
+extends: [policy/org.yaml, policy/team.yaml]
+repositoryPath: ./repo
+owners: ["@a", "@b"]
+`)
+		fileSrc, err := config.NewFileSource(path, "")
+		require.NoError(t, err)
+
+		// when
+		var cfg testConfig
+		err = config.NewLoader(fileSrc).Load(&cfg)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, check.Error, cfg.FailureLevel)
+		assert.Equal(t, []string{"@a", "@b"}, cfg.Owners)
+		assert.Equal(t, time.Minute, cfg.Github.HTTPRequestTimeout)
+		assert.EqualValues(t, 2, cfg.Github.AppID)
+	})
+
+	t.Run("Should attribute values to the file which set them", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "policy", "org.yaml"), `
+failureLevel: error
+github:
+  httpRequestTimeout: 1m
+  appID: 1
+`)
+		path := writeConfigFile(t, dir, `
+extends: policy/org.yaml
+github:
+  appID: 2
+`)
+		fileSrc, err := config.NewFileSource(path, dir)
+		require.NoError(t, err)
+
+		// when
+		values := config.NewLoader(fileSrc).Describe(&testConfig{})
+
+		// then
+		sources := map[string]string{}
+		for _, v := range values {
+			sources[v.Field.Key()] = v.Source
+		}
+		assert.Equal(t, map[string]string{
+			"RepositoryPath":            "",
+			"FailureLevel":              "file (" + filepath.Join("policy", "org.yaml") + ")",
+			"Owners":                    config.DefaultSourceName,
+			"Github.HTTPRequestTimeout": "file (" + filepath.Join("policy", "org.yaml") + ")",
+			"Github.AppID":              "file (" + path + ")",
+		}, sources)
+	})
+
+	t.Run("Should detect cycles", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "base.yaml"), "extends: .codeowners-validator.yaml")
+		path := writeConfigFile(t, dir, "extends: base.yaml")
+
+		// when
+		_, err := config.NewFileSource(path, "")
+
+		// then
+		assert.ErrorContains(t, err, "is extended in a cycle")
+	})
+
+	t.Run("Should reject sensitive keys from extended files", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "base.yaml"), `
+github:
+  baseURL: https://attacker.example.com
+`)
+		path := writeConfigFile(t, dir, "extends: base.yaml")
+		fileSrc, err := config.NewFileSource(path, "")
+		require.NoError(t, err)
+
+		type sensitiveConfig struct {
+			Github struct {
+				BaseURL string `envconfig:"optional" sensitive:"true"`
+			}
+		}
+
+		// when
+		err = fileSrc.Validate(&sensitiveConfig{})
+
+		// then
+		assert.EqualError(t, err, "keys in config file "+path+" can be set only via flags or environment variables: github.baseURL")
+	})
+
+	t.Run("Should reject files outside the repository", func(t *testing.T) {
+		// given
+		parent := t.TempDir()
+		repo := filepath.Join(parent, "repo")
+		writeFile(t, filepath.Join(parent, "secret.yaml"), "token: s3cr3t")
+		require.NoError(t, os.MkdirAll(repo, 0o700))
+		require.NoError(t, os.Symlink(filepath.Join(parent, "secret.yaml"), filepath.Join(repo, "link.yaml")))
+
+		for _, base := range []string{filepath.Join(parent, "secret.yaml"), "../secret.yaml", "policy/../../secret.yaml", "link.yaml"} {
+			path := writeConfigFile(t, repo, "extends: "+base)
+
+			// when
+			_, err := config.NewFileSource(path, repo)
+
+			// then
+			assert.EqualError(t, err, fmt.Sprintf("config file %s: only files from the repository can be extended, got %q", path, base))
+		}
+	})
+
+	t.Run("Should not reveal content of malformed files", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "passwd"), "root:x:0:0:root:/root:/bin/bash\n")
+		path := writeConfigFile(t, dir, "extends: passwd")
+
+		// when
+		_, err := config.NewFileSource(path, dir)
+
+		// then
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "root:x")
+		assert.Contains(t, err.Error(), "config file "+filepath.Join(dir, "passwd")+" is not a valid YAML mapping")
+	})
+
+	t.Run("Should reject remote files", func(t *testing.T) {
+		// given
+		path := writeConfigFile(t, t.TempDir(), "extends: https://example.com/policy.yaml")
+
+		// when
+		_, err := config.NewFileSource(path, "")
+
+		// then
+		assert.ErrorContains(t, err, `only local files can be extended, got "https://example.com/policy.yaml"`)
+	})
+}
+
+func TestDiscoverFile(t *testing.T) {
+	t.Run("Should find the config file in the repository root", func(t *testing.T) {
+		// given
+		repo := t.TempDir()
+		exp := writeConfigFile(t, repo, "checks: [files]")
+
+		// when
+		got, err := config.DiscoverFile(repo)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, exp, got)
+	})
+
+	t.Run("Should return empty path if there is no config file", func(t *testing.T) {
+		// when
+		got, err := config.DiscoverFile(t.TempDir())
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
+
+func writeConfigFile(t *testing.T, dir, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, config.FileNames[0])
+	writeFile(t, path, content)
+	return path
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
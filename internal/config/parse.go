@@ -0,0 +1,138 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unmarshaler allows custom parsing of a raw configuration value.
+// It's the same interface as the one used by the `envconfig` library.
+type Unmarshaler interface {
+	Unmarshal(s string) error
+}
+
+const (
+	sliceSeparator        = ","
+	sliceDefaultSeparator = ";"
+)
+
+var (
+	durationType    = reflect.TypeOf(time.Duration(0))
+	unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+)
+
+func isUnmarshaler(t reflect.Type) bool {
+	return t.Implements(unmarshalerType) || reflect.PtrTo(t).Implements(unmarshalerType)
+}
+
+// isScalar returns true if a given type can be represented by a single raw value.
+func isScalar(t reflect.Type) bool {
+	if isUnmarshaler(t) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Struct, reflect.Array, reflect.Interface:
+		return false
+	case reflect.Ptr:
+		return isScalar(t.Elem())
+	default:
+		return true
+	}
+}
+
+// setField parses a raw value and sets it on a given field.
+// Lists of scalar values are comma separated, complex types are represented as JSON.
+func setField(f Field, raw string, usingDefault bool) error {
+	var err error
+	switch {
+	case f.IsSlice() && isScalar(f.Type.Elem()):
+		sep := sliceSeparator
+		if usingDefault {
+			sep = sliceDefaultSeparator
+		}
+		err = setSlice(f.value, raw, sep)
+	case isScalar(f.Type):
+		err = setScalar(f.value, raw)
+	default:
+		err = json.Unmarshal([]byte(raw), f.value.Addr().Interface())
+	}
+	if err != nil {
+		return fmt.Errorf("while parsing value %q for %s: %v", raw, f.Key(), err)
+	}
+	return nil
+}
+
+func setSlice(v reflect.Value, raw, sep string) error {
+	slice := reflect.MakeSlice(v.Type(), 0, 0)
+	for _, token := range strings.Split(raw, sep) {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		el := reflect.New(v.Type().Elem()).Elem()
+		if err := setScalar(el, token); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, el)
+	}
+	v.Set(slice)
+	return nil
+}
+
+func setScalar(v reflect.Value, raw string) error {
+	if isUnmarshaler(v.Type()) {
+		if v.Kind() == reflect.Ptr {
+			v.Set(reflect.New(v.Type().Elem()))
+			return v.Interface().(Unmarshaler).Unmarshal(raw)
+		}
+		return v.Addr().Interface().(Unmarshaler).Unmarshal(raw)
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		fl, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(fl)
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		return setScalar(v.Elem(), raw)
+	default:
+		return fmt.Errorf("kind %v not supported", v.Kind())
+	}
+	return nil
+}
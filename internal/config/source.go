@@ -0,0 +1,141 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Source provides raw configuration values.
+type Source interface {
+	// Name returns human-readable name of the source.
+	Name() string
+	// Lookup returns the raw value of a given field and reports whether it was set.
+	Lookup(f Field) (string, bool)
+}
+
+// fieldNamer is implemented by sources which read options from several places, e.g. extended files,
+// so the place from which a given option was read can be reported.
+type fieldNamer interface {
+	NameOf(f Field) string
+}
+
+// EnvSource reads configuration from environment variables.
+// Supports also envs prefix if set via the ENVS_PREFIX environment variable.
+type EnvSource struct {
+	prefix string
+}
+
+// NewEnvSource returns a new EnvSource instance.
+func NewEnvSource() *EnvSource {
+	return &EnvSource{prefix: os.Getenv("ENVS_PREFIX")}
+}
+
+// Name returns human-readable name of the source.
+func (*EnvSource) Name() string {
+	return "env"
+}
+
+// Lookup returns the environment variable value for a given field.
+func (s *EnvSource) Lookup(f Field) (string, bool) {
+	for _, key := range s.keys(f) {
+		if val := os.Getenv(key); val != "" {
+			return val, true
+		}
+	}
+	return "", false
+}
+
+// EnvName returns the environment variable name for a given field including the prefix if set.
+func (s *EnvSource) EnvName(f Field) string {
+	if s.prefix == "" {
+		return f.EnvName()
+	}
+	return strings.ToUpper(s.prefix) + "_" + f.EnvName()
+}
+
+// keys returns all accepted names. To stay backward compatible with the `envconfig` library,
+// lower-case names and names without underscores between words are accepted too.
+func (s *EnvSource) keys(f Field) []string {
+	name := s.EnvName(f)
+
+	var segments []string
+	if s.prefix != "" {
+		segments = append(segments, s.prefix)
+	}
+	segments = append(segments, f.Path...)
+	joined := strings.ToUpper(strings.Join(segments, "_"))
+
+	return []string{name, strings.ToLower(name), joined, strings.ToLower(joined)}
+}
+
+// FlagSource reads configuration from CLI flags which were explicitly set by a user.
+type FlagSource struct {
+	flags *pflag.FlagSet
+}
+
+// NewFlagSource returns a new FlagSource instance.
+func NewFlagSource(flags *pflag.FlagSet) *FlagSource {
+	return &FlagSource{flags: flags}
+}
+
+// Name returns human-readable name of the source.
+func (*FlagSource) Name() string {
+	return "flag"
+}
+
+// Lookup returns the flag value for a given field.
+func (s *FlagSource) Lookup(f Field) (string, bool) {
+	flag := s.flags.Lookup(f.FlagName())
+	if flag == nil || !flag.Changed {
+		return "", false
+	}
+
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		return strings.Join(slice.GetSlice(), sliceSeparator), true
+	}
+	return flag.Value.String(), true
+}
+
+// RegisterFlags defines a CLI flag for each option of a given configuration struct.
+// Flags which are already defined are skipped, so the same struct can be shared by different checks.
+func RegisterFlags(flags *pflag.FlagSet, conf interface{}) {
+	for _, f := range Fields(conf) {
+		name := f.FlagName()
+		if flags.Lookup(name) != nil {
+			continue
+		}
+
+		switch {
+		case f.Type.Kind() == reflect.Bool:
+			def, _ := strconv.ParseBool(f.Default)
+			flags.Bool(name, def, f.Description)
+		case f.IsSlice() && isScalar(f.Type.Elem()):
+			var def []string
+			if f.Default != "" {
+				def = strings.Split(f.Default, sliceDefaultSeparator)
+			}
+			flags.StringSlice(name, def, f.Description)
+		default:
+			flags.String(name, f.Default, f.Description)
+		}
+	}
+}
+
+// MapSource reads configuration from a map keyed by the environment variable names, e.g. OWNER_CHECKER_REPOSITORY.
+// It's used when the configuration is provided programmatically.
+type MapSource map[string]string
+
+// Name returns human-readable name of the source.
+func (MapSource) Name() string {
+	return "options"
+}
+
+// Lookup returns the map value for a given field.
+func (s MapSource) Lookup(f Field) (string, bool) {
+	val, found := s[f.EnvName()]
+	return val, found && val != ""
+}
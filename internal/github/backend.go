@@ -0,0 +1,134 @@
+package github
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// RESTBackend calls the GitHub REST API. It executes one request per team and per user.
+	RESTBackend = "rest"
+	// GraphQLBackend calls the GitHub GraphQL API. It fetches teams, members and users in batches.
+	GraphQLBackend = "graphql"
+	// SnapshotBackend reads the organization snapshot file. It doesn't call GitHub at all.
+	SnapshotBackend = "snapshot"
+)
+
+// AccountType describes the type of GitHub account.
+type AccountType string
+
+const (
+	UserAccount         AccountType = "User"
+	OrganizationAccount AccountType = "Organization"
+	BotAccount          AccountType = "Bot"
+)
+
+// User describes an existing GitHub account.
+type User struct {
+	Login string      `json:"login"`
+	Type  AccountType `json:"type"`
+}
+
+// Permission describes the access level of a team or a user to a repository.
+type Permission string
+
+const (
+	PermissionNone     Permission = ""
+	PermissionRead     Permission = "pull"
+	PermissionTriage   Permission = "triage"
+	PermissionWrite    Permission = "push"
+	PermissionMaintain Permission = "maintain"
+	PermissionAdmin    Permission = "admin"
+)
+
+// CanReview returns true if the permission allows approving pull requests.
+func (p Permission) CanReview() bool {
+	switch p {
+	case PermissionWrite, PermissionMaintain, PermissionAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
+// Backend provides the GitHub data required to validate CODEOWNERS owners.
+type Backend interface {
+	// Scopes returns the OAuth scopes granted to the token. It also verifies that a given repository can be accessed.
+	// Scopes are empty when authorized as a GitHub App.
+	Scopes(ctx context.Context, org, repo string) ([]string, error)
+	// OwnerType returns the type of the account which owns a given repository.
+	OwnerType(ctx context.Context, owner, repo string) (AccountType, error)
+	// Teams returns slugs of all teams in a given organization.
+	Teams(ctx context.Context, org string) ([]string, error)
+	// TeamRepoPermission returns the permission of a given team to a given repository.
+	// Returns PermissionNone if the team doesn't have access to the repository.
+	TeamRepoPermission(ctx context.Context, org, slug, repo string) (Permission, error)
+	// UserRepoPermission returns the effective permission of a given user to a given repository,
+	// including the permissions granted by teams and organization base permissions.
+	// Returns PermissionNone if the user doesn't have access to the repository.
+	UserRepoPermission(ctx context.Context, org, repo, login string) (Permission, error)
+	// OrgMembers returns logins of all members of a given organization.
+	OrgMembers(ctx context.Context, org string) ([]string, error)
+	// Users returns the accounts of those of given logins that exist.
+	Users(ctx context.Context, logins []string) ([]User, error)
+}
+
+// APIError is returned by backends when a GitHub API call fails.
+type APIError struct {
+	// StatusCode is the HTTP status code, 0 if it's unknown.
+	StatusCode int
+	// RateLimited is true if the call was rejected because the rate limit was reached.
+	RateLimited bool
+	Err         error
+}
+
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// NewBackend returns a backend of a given type. The API backends are wrapped with Cache if the cache directory is set.
+// Returns true if the OAuth scopes of the token can be checked, which is not possible for GitHub Apps and snapshots.
+func NewBackend(ctx context.Context, cfg *ClientConfig) (Backend, bool, error) {
+	var (
+		backend Backend
+		isApp   bool
+	)
+	switch cfg.Backend {
+	case RESTBackend, "":
+		ghClient, app, err := NewClient(ctx, cfg)
+		if err != nil {
+			return nil, false, err
+		}
+		backend, isApp = NewREST(ghClient), app
+	case GraphQLBackend:
+		httpClient, app, err := newHTTPClient(ctx, cfg)
+		if err != nil {
+			return nil, false, err
+		}
+		ghClient, err := newGitHubClient(httpClient, cfg)
+		if err != nil {
+			return nil, false, err
+		}
+		backend, isApp = NewGraphQL(httpClient, graphQLEndpoint(cfg.BaseURL), NewREST(ghClient)), app
+	case SnapshotBackend:
+		if err := cfg.Validate(); err != nil {
+			return nil, false, err
+		}
+		snapshot, err := LoadSnapshot(cfg.SnapshotPath)
+		if err != nil {
+			return nil, false, err
+		}
+		return NewOffline(snapshot), false, nil
+	default:
+		return nil, false, fmt.Errorf("not supported backend %q, allowed values: %s, %s, %s", cfg.Backend, RESTBackend, GraphQLBackend, SnapshotBackend)
+	}
+
+	if cfg.CacheDir != "" {
+		backend = NewCache(backend, cfg.CacheDir, APIHost(cfg.BaseURL), CredentialsFingerprint(cfg), cfg.CacheTTL)
+	}
+	return backend, !isApp, nil
+}
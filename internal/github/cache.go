@@ -0,0 +1,244 @@
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type cacheRecord struct {
+	StoredAt time.Time       `json:"storedAt"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// Cache implements Backend by storing results of another backend on disk. Records are kept in JSON files
+// under the API host, credentials and organization directories, and are refetched when they are older than the TTL.
+// Failed calls are not cached. The token scopes and repository access are always checked by the wrapped backend,
+// so a revoked token is not accepted based on the cached records.
+type Cache struct {
+	next Backend
+	dir  string
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// NewCache returns a new Cache instance. Records are stored in a subdirectory of dir named after the API host
+// and the fingerprint of given credentials, see CredentialsFingerprint, so records fetched with one credentials
+// are never used with other ones. The TTL needs to be positive, otherwise records are always refetched.
+func NewCache(next Backend, dir, host, credentials string, ttl time.Duration) *Cache {
+	return &Cache{
+		next: next,
+		dir:  filepath.Join(dir, cacheKey(host), cacheKey(credentials)),
+		ttl:  ttl,
+		now:  time.Now,
+	}
+}
+
+// CredentialsFingerprint returns a name which identifies the configured credentials without revealing them.
+func CredentialsFingerprint(cfg *ClientConfig) string {
+	id := fmt.Sprintf("token:%s", cfg.AccessToken)
+	if cfg.AccessToken == "" && cfg.AppID != 0 {
+		id = fmt.Sprintf("app:%d:%d", cfg.AppID, cfg.AppInstallationID)
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Scopes is not cached, as it's used to verify that the token is still valid and can access the repository.
+func (c *Cache) Scopes(ctx context.Context, org, repo string) ([]string, error) {
+	return c.next.Scopes(ctx, org, repo)
+}
+
+func (c *Cache) OwnerType(ctx context.Context, owner, repo string) (AccountType, error) {
+	var ownerType AccountType
+	err := c.cached(&ownerType, []string{"orgs", owner, "repos", repo, "owner-type"}, func() (interface{}, error) {
+		return c.next.OwnerType(ctx, owner, repo)
+	})
+	return ownerType, err
+}
+
+func (c *Cache) Teams(ctx context.Context, org string) ([]string, error) {
+	var teams []string
+	err := c.cached(&teams, []string{"orgs", org, "teams"}, func() (interface{}, error) {
+		return c.next.Teams(ctx, org)
+	})
+	return teams, err
+}
+
+func (c *Cache) TeamRepoPermission(ctx context.Context, org, slug, repo string) (Permission, error) {
+	var perm Permission
+	err := c.cached(&perm, []string{"orgs", org, "repos", repo, "teams", slug}, func() (interface{}, error) {
+		return c.next.TeamRepoPermission(ctx, org, slug, repo)
+	})
+	return perm, err
+}
+
+func (c *Cache) UserRepoPermission(ctx context.Context, org, repo, login string) (Permission, error) {
+	var perm Permission
+	err := c.cached(&perm, []string{"orgs", org, "repos", repo, "users", login}, func() (interface{}, error) {
+		return c.next.UserRepoPermission(ctx, org, repo, login)
+	})
+	return perm, err
+}
+
+func (c *Cache) OrgMembers(ctx context.Context, org string) ([]string, error) {
+	var members []string
+	err := c.cached(&members, []string{"orgs", org, "members"}, func() (interface{}, error) {
+		return c.next.OrgMembers(ctx, org)
+	})
+	return members, err
+}
+
+// Users returns cached results, only users which are not cached are fetched from the wrapped backend.
+// Users don't belong to an organization, so they are stored directly under the API host and credentials directory.
+// The account type is stored for each login, and it's empty if the account doesn't exist.
+func (c *Cache) Users(ctx context.Context, logins []string) ([]User, error) {
+	var (
+		existing = map[string]AccountType{}
+		missed   []string
+	)
+	for _, login := range logins {
+		var accountType AccountType
+		if !c.load(&accountType, userKey(login)) {
+			missed = append(missed, login)
+			continue
+		}
+		if accountType != "" {
+			existing[login] = accountType
+		}
+	}
+
+	if len(missed) > 0 {
+		fetched, err := c.next.Users(ctx, missed)
+		if err != nil {
+			return nil, err
+		}
+
+		found := map[string]AccountType{}
+		for _, u := range fetched {
+			found[u.Login] = u.Type
+			existing[u.Login] = u.Type
+		}
+		for _, login := range missed {
+			c.store(found[login], userKey(login))
+		}
+	}
+
+	// keep the order of given logins
+	var out []User
+	for _, login := range logins {
+		if accountType, found := existing[login]; found {
+			out = append(out, User{Login: login, Type: accountType})
+		}
+	}
+	return out, nil
+}
+
+// cached decodes the cached record into out, or calls fetch and caches its result.
+func (c *Cache) cached(out interface{}, key []string, fetch func() (interface{}, error)) error {
+	if c.load(out, key) {
+		return nil
+	}
+
+	val, err := fetch()
+	if err != nil {
+		return err
+	}
+	c.store(val, key)
+
+	// decode the fetched value the same way as the cached one
+	raw, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// load returns true if a fresh record was found and decoded into out.
+func (c *Cache) load(out interface{}, key []string) bool {
+	raw, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return false
+	}
+
+	var rec cacheRecord
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return false
+	}
+	if c.now().Sub(rec.StoredAt) > c.ttl {
+		return false
+	}
+
+	return json.Unmarshal(rec.Value, out) == nil
+}
+
+// store saves a given value. The cache is only an optimization, so failures are ignored
+// and the value is fetched again on the next run.
+func (c *Cache) store(val interface{}, key []string) {
+	raw, err := json.Marshal(val)
+	if err != nil {
+		return
+	}
+	rec, err := json.Marshal(cacheRecord{StoredAt: c.now(), Value: raw})
+	if err != nil {
+		return
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+
+	// write to a temporary file first, so concurrent runs never read a partially written record
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(rec)
+	if closeErr := tmp.Close(); err != nil || closeErr != nil {
+		return
+	}
+	_ = os.Rename(tmp.Name(), path)
+}
+
+func (c *Cache) path(key []string) string {
+	parts := []string{c.dir}
+	for _, k := range key {
+		parts = append(parts, cacheKey(k))
+	}
+	return filepath.Join(parts...) + ".json"
+}
+
+func userKey(login string) []string {
+	return []string{"users", login}
+}
+
+// cacheKey returns a name safe to use as a file name. GitHub names are case-insensitive, so the name is lowercased.
+func cacheKey(name string) string {
+	key := url.PathEscape(strings.ToLower(name))
+	if key == "" || key == "." || key == ".." {
+		key = "_" + key
+	}
+	return key
+}
+
+// APIHost returns the host of the GitHub API configured by a given base URL.
+func APIHost(baseURL string) string {
+	if baseURL == "" {
+		return "api.github.com"
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return baseURL
+	}
+	return u.Host
+}
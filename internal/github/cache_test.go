@@ -0,0 +1,203 @@
+package github_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.szostok.io/codeowners-validator/internal/github"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	// given
+	ctx := context.Background()
+	dir := t.TempDir()
+	backend := &countingBackend{}
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sut := github.NewCache(backend, dir, "api.github.com", "token-a", time.Hour)
+	sut.SetNow(func() time.Time { return now })
+
+	lookup := func() {
+		t.Helper()
+
+		teams, err := sut.Teams(ctx, "Acme")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"devs", "admins"}, teams)
+
+		members, err := sut.OrgMembers(ctx, "acme")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"member"}, members)
+
+		perm, err := sut.TeamRepoPermission(ctx, "acme", "devs", "app")
+		require.NoError(t, err)
+		assert.Equal(t, github.PermissionWrite, perm)
+
+		perm, err = sut.TeamRepoPermission(ctx, "acme", "admins", "app")
+		require.NoError(t, err)
+		assert.Equal(t, github.PermissionNone, perm)
+
+		perm, err = sut.UserRepoPermission(ctx, "acme", "app", "member")
+		require.NoError(t, err)
+		assert.Equal(t, github.PermissionMaintain, perm)
+
+		ownerType, err := sut.OwnerType(ctx, "acme", "app")
+		require.NoError(t, err)
+		assert.Equal(t, github.OrganizationAccount, ownerType)
+
+		scopes, err := sut.Scopes(ctx, "acme", "app")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"read:org"}, scopes)
+	}
+
+	// when
+	lookup()
+	now = now.Add(59 * time.Minute)
+	lookup()
+
+	// then
+	assert.Equal(t, 8, backend.calls, "records except scopes should be fetched only once within the TTL")
+	assert.FileExists(t, filepath.Join(dir, "api.github.com", "token-a", "orgs", "acme", "teams.json"))
+
+	// when
+	now = now.Add(2 * time.Minute)
+	lookup()
+
+	// then
+	assert.Equal(t, 15, backend.calls, "records should be fetched again after the TTL")
+}
+
+func TestCacheIsolatesCredentials(t *testing.T) {
+	// given
+	ctx := context.Background()
+	dir := t.TempDir()
+	backend := &countingBackend{}
+	first := github.NewCache(backend, dir, "api.github.com", "token-a", time.Hour)
+	second := github.NewCache(backend, dir, "api.github.com", "token-b", time.Hour)
+
+	// when
+	_, err := first.Teams(ctx, "acme")
+	require.NoError(t, err)
+	_, err = second.Teams(ctx, "acme")
+	require.NoError(t, err)
+
+	// then
+	assert.Equal(t, 2, backend.calls, "records fetched with other credentials should not be used")
+}
+
+func TestCredentialsFingerprint(t *testing.T) {
+	// given
+	tokenA := github.CredentialsFingerprint(&github.ClientConfig{AccessToken: "token-a"})
+	tokenB := github.CredentialsFingerprint(&github.ClientConfig{AccessToken: "token-b"})
+	app := github.CredentialsFingerprint(&github.ClientConfig{AppID: 1, AppInstallationID: 2})
+	otherInstallation := github.CredentialsFingerprint(&github.ClientConfig{AppID: 1, AppInstallationID: 3})
+
+	// then
+	assert.Len(t, tokenA, 32)
+	assert.NotContains(t, tokenA, "token-a")
+	assert.Equal(t, tokenA, github.CredentialsFingerprint(&github.ClientConfig{AccessToken: "token-a"}))
+	assert.NotEqual(t, tokenA, tokenB)
+	assert.NotEqual(t, app, otherInstallation)
+}
+
+func TestCacheUsers(t *testing.T) {
+	// given
+	ctx := context.Background()
+	backend := &countingBackend{}
+	sut := github.NewCache(backend, t.TempDir(), "ghe.example.com", "token-a", time.Hour)
+
+	// when
+	existing, err := sut.Users(ctx, []string{"member", "ghost"})
+	require.NoError(t, err)
+	assert.Equal(t, []github.User{{Login: "member", Type: github.UserAccount}}, existing)
+
+	existing, err = sut.Users(ctx, []string{"ghost", "acme", "member"})
+	require.NoError(t, err)
+
+	// then
+	assert.Equal(t, []github.User{
+		{Login: "acme", Type: github.OrganizationAccount},
+		{Login: "member", Type: github.UserAccount},
+	}, existing)
+	assert.Equal(t, [][]string{{"member", "ghost"}, {"acme"}}, backend.usersCalls, "only users which are not cached should be fetched")
+}
+
+func TestCacheDoesNotStoreErrors(t *testing.T) {
+	// given
+	ctx := context.Background()
+	dir := t.TempDir()
+	backend := &countingBackend{err: errors.New("connection refused")}
+	sut := github.NewCache(backend, dir, "api.github.com", "token-a", time.Hour)
+
+	// when
+	_, err := sut.Teams(ctx, "acme")
+
+	// then
+	assert.EqualError(t, err, "connection refused")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+type countingBackend struct {
+	err        error
+	calls      int
+	usersCalls [][]string
+}
+
+func (b *countingBackend) Scopes(context.Context, string, string) ([]string, error) {
+	b.calls++
+	return []string{"read:org"}, b.err
+}
+
+func (b *countingBackend) OwnerType(context.Context, string, string) (github.AccountType, error) {
+	b.calls++
+	return github.OrganizationAccount, b.err
+}
+
+func (b *countingBackend) Teams(context.Context, string) ([]string, error) {
+	b.calls++
+	if b.err != nil {
+		return nil, b.err
+	}
+	return []string{"devs", "admins"}, nil
+}
+
+func (b *countingBackend) TeamRepoPermission(_ context.Context, _, slug, _ string) (github.Permission, error) {
+	b.calls++
+	if slug == "devs" {
+		return github.PermissionWrite, b.err
+	}
+	return github.PermissionNone, b.err
+}
+
+func (b *countingBackend) UserRepoPermission(context.Context, string, string, string) (github.Permission, error) {
+	b.calls++
+	return github.PermissionMaintain, b.err
+}
+
+func (b *countingBackend) OrgMembers(context.Context, string) ([]string, error) {
+	b.calls++
+	return []string{"member"}, b.err
+}
+
+func (b *countingBackend) Users(_ context.Context, logins []string) ([]github.User, error) {
+	b.usersCalls = append(b.usersCalls, logins)
+
+	var out []github.User
+	for _, l := range logins {
+		switch l {
+		case "member":
+			out = append(out, github.User{Login: l, Type: github.UserAccount})
+		case "acme":
+			out = append(out, github.User{Login: l, Type: github.OrganizationAccount})
+		}
+	}
+	return out, b.err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/bradleyfalzon/ghinstallation/v2"
@@ -15,19 +16,33 @@ import (
 )
 
 type ClientConfig struct {
-	AccessToken string `envconfig:"optional"`
-
-	AppID             int64  `envconfig:"optional"`
-	AppPrivateKey     string `envconfig:"optional"`
-	AppInstallationID int64  `envconfig:"optional"`
-
-	BaseURL            string        `envconfig:"optional"`
-	UploadURL          string        `envconfig:"optional"`
-	HTTPRequestTimeout time.Duration `envconfig:"default=30s"`
+	AccessToken string `envconfig:"optional" secret:"true" desc:"GitHub access token."`
+
+	AppID             int64  `envconfig:"optional" desc:"GitHub App ID for authentication. Replaces the access token."`
+	AppPrivateKey     string `envconfig:"optional" secret:"true" desc:"GitHub App private key in PEM format."`
+	AppInstallationID int64  `envconfig:"optional" desc:"GitHub App Installation ID."`
+
+	BaseURL             string        `envconfig:"optional" sensitive:"true" desc:"GitHub base URL for API requests."`
+	UploadURL           string        `envconfig:"optional" sensitive:"true" desc:"GitHub upload URL for uploading files."`
+	HTTPRequestTimeout  time.Duration `envconfig:"default=30s" desc:"Timeout for a single GitHub API request."`
+	MaxRetries          int           `envconfig:"default=3" desc:"Maximum number of retries of GitHub API requests which failed because of server or network errors."`
+	RateLimitWaitBudget time.Duration `envconfig:"default=1m" desc:"Maximum total time spent waiting for GitHub API rate limit resets. If exceeded, the rate limit error is reported."`
+
+	Backend      string        `envconfig:"default=rest" desc:"GitHub API used to validate owners. Possible values: rest, graphql, snapshot."`
+	SnapshotPath string        `envconfig:"optional" sensitive:"true" desc:"Path to the organization snapshot file. Required by the snapshot backend."`
+	CacheDir     string        `envconfig:"optional" sensitive:"true" desc:"Directory in which GitHub API lookups are cached. Caching is disabled if not set."`
+	CacheTTL     time.Duration `envconfig:"default=1h" desc:"How long cached GitHub API lookups are used before they are fetched again."`
 }
 
 // Validate validates if provided client options are valid.
 func (c *ClientConfig) Validate() error {
+	if c.Backend == SnapshotBackend {
+		if c.SnapshotPath == "" {
+			return errors.New("GitHub SNAPSHOT_PATH is required with the snapshot backend")
+		}
+		return nil
+	}
+
 	if c.AccessToken == "" && c.AppID == 0 {
 		return errors.New("GitHub authorization is required, provide ACCESS_TOKEN or APP_ID")
 	}
@@ -49,47 +64,74 @@ func (c *ClientConfig) Validate() error {
 }
 
 func NewClient(ctx context.Context, cfg *ClientConfig) (ghClient *github.Client, isApp bool, err error) {
-	if err := cfg.Validate(); err != nil {
+	httpClient, isApp, err := newHTTPClient(ctx, cfg)
+	if err != nil {
 		return nil, false, err
 	}
 
-	httpClient := http.DefaultClient
+	ghClient, err = newGitHubClient(httpClient, cfg)
+	return ghClient, isApp, err
+}
+
+// newGitHubClient returns the REST API client which uses a given HTTP client.
+func newGitHubClient(httpClient *http.Client, cfg *ClientConfig) (*github.Client, error) {
+	baseURL, uploadURL := cfg.BaseURL, cfg.UploadURL
+
+	if baseURL == "" {
+		return github.NewClient(httpClient), nil
+	}
+
+	if uploadURL == "" { // often the baseURL is same as the uploadURL, so we do not require to provide both of them
+		uploadURL = baseURL
+	}
+
+	bURL, uURL := url.CanonicalPath(baseURL), url.CanonicalPath(uploadURL)
+	return github.NewEnterpriseClient(bURL, uURL, httpClient)
+}
 
+// newHTTPClient returns the HTTP client authorized with the configured access token or GitHub App.
+func newHTTPClient(ctx context.Context, cfg *ClientConfig) (httpClient *http.Client, isApp bool, err error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, false, err
+	}
+
+	var tr http.RoundTripper
 	if cfg.AccessToken != "" {
-		httpClient = oauth2.NewClient(ctx, oauth2.StaticTokenSource(
+		tr = oauth2.NewClient(ctx, oauth2.StaticTokenSource(
 			&oauth2.Token{AccessToken: cfg.AccessToken},
-		))
+		)).Transport
 	} else if cfg.AppID != 0 {
-		httpClient, err = createAppInstallationHTTPClient(cfg)
+		tr, err = createAppInstallationTransport(cfg)
 		isApp = true
 		if err != nil {
 			return
 		}
 	}
-	httpClient.Timeout = cfg.HTTPRequestTimeout
 
-	baseURL, uploadURL := cfg.BaseURL, cfg.UploadURL
+	// the timeout is applied by the retry transport to each attempt, so waiting for the retries doesn't count towards it
+	return &http.Client{Transport: newRetryTransport(tr, cfg)}, isApp, nil
+}
 
+// graphQLEndpoint returns the GraphQL API endpoint for a given REST API base URL.
+func graphQLEndpoint(baseURL string) string {
 	if baseURL == "" {
-		ghClient = github.NewClient(httpClient)
-		return
+		return "https://api.github.com/graphql"
 	}
 
-	if uploadURL == "" { // often the baseURL is same as the uploadURL, so we do not require to provide both of them
-		uploadURL = baseURL
+	// GitHub Enterprise serves REST API under /api/v3/ and GraphQL API under /api/graphql
+	bURL := strings.TrimSuffix(url.CanonicalPath(baseURL), "v3/")
+	if !strings.HasSuffix(bURL, "/api/") {
+		bURL += "api/"
 	}
-
-	bURL, uURL := url.CanonicalPath(baseURL), url.CanonicalPath(uploadURL)
-	ghClient, err = github.NewEnterpriseClient(bURL, uURL, httpClient)
-	return
+	return bURL + "graphql"
 }
 
-func createAppInstallationHTTPClient(cfg *ClientConfig) (client *http.Client, err error) {
+func createAppInstallationTransport(cfg *ClientConfig) (http.RoundTripper, error) {
 	tr := http.DefaultTransport
 	itr, err := ghinstallation.New(tr, cfg.AppID, cfg.AppInstallationID, []byte(cfg.AppPrivateKey))
 	if err != nil {
 		return nil, err
 	}
 
-	return &http.Client{Transport: itr}, nil
+	return itr, nil
 }
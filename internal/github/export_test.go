@@ -0,0 +1,20 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+var GraphQLEndpoint = graphQLEndpoint
+
+func NewRetryTransport(next http.RoundTripper, cfg *ClientConfig, sleep func(context.Context, time.Duration) error, now func() time.Time) http.RoundTripper {
+	tr := newRetryTransport(next, cfg)
+	tr.sleep = sleep
+	tr.now = now
+	return tr
+}
+
+func (c *Cache) SetNow(now func() time.Time) {
+	c.now = now
+}
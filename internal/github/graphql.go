@@ -0,0 +1,545 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	// maxUsersPerQuery limits the number of users fetched in a single query.
+	maxUsersPerQuery = 100
+
+	graphQLNotFound    = "NOT_FOUND"
+	graphQLRateLimited = "RATE_LIMITED"
+)
+
+const (
+	repositoryQuery = `query($org: String!, $repo: String!) {
+  repository(owner: $org, name: $repo) { id }
+}`
+
+	ownerTypeQuery = `query($owner: String!, $repo: String!) {
+  repository(owner: $owner, name: $repo) { owner { __typename } }
+}`
+
+	teamsQuery = `query($org: String!, $cursor: String) {
+  organization(login: $org) {
+    teams(first: 100, after: $cursor) {
+      nodes { slug }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+	// teamsPermissionsQuery fetches teams with their permissions to repositories matching a given name.
+	teamsPermissionsQuery = `query($org: String!, $repo: String!, $cursor: String) {
+  organization(login: $org) {
+    teams(first: 100, after: $cursor) {
+      nodes {
+        slug
+        repositories(first: 100, query: $repo) {
+          edges { permission node { name } }
+          pageInfo { hasNextPage endCursor }
+        }
+      }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+	// teamRepositoriesQuery fetches the next page of the team repositories matching a given name.
+	teamRepositoriesQuery = `query($org: String!, $slug: String!, $repo: String!, $cursor: String) {
+  organization(login: $org) {
+    team(slug: $slug) {
+      repositories(first: 100, after: $cursor, query: $repo) {
+        edges { permission node { name } }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }
+}`
+
+	// collaboratorsQuery fetches all collaborators with their permissions to the repository.
+	collaboratorsQuery = `query($org: String!, $repo: String!, $cursor: String) {
+  repository(owner: $org, name: $repo) {
+    collaborators(first: 100, after: $cursor) {
+      edges { permission node { login } }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+	membersQuery = `query($org: String!, $cursor: String) {
+  organization(login: $org) {
+    membersWithRole(first: 100, after: $cursor) {
+      nodes { login }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+)
+
+// graphQLPermissions maps GraphQL repository permissions to their REST API names.
+var graphQLPermissions = map[string]Permission{
+	"READ":     PermissionRead,
+	"TRIAGE":   PermissionTriage,
+	"WRITE":    PermissionWrite,
+	"MAINTAIN": PermissionMaintain,
+	"ADMIN":    PermissionAdmin,
+}
+
+type (
+	graphQLRequest struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables,omitempty"`
+	}
+
+	graphQLResponse struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphQLError  `json:"errors"`
+	}
+
+	graphQLError struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	}
+
+	pageInfo struct {
+		HasNextPage bool   `json:"hasNextPage"`
+		EndCursor   string `json:"endCursor"`
+	}
+
+	teamRepositories struct {
+		Edges []struct {
+			Permission string `json:"permission"`
+			Node       struct {
+				Name string `json:"name"`
+			} `json:"node"`
+		} `json:"edges"`
+		PageInfo pageInfo `json:"pageInfo"`
+	}
+
+	teamsResponse struct {
+		Organization *struct {
+			Teams struct {
+				Nodes []struct {
+					Slug         string           `json:"slug"`
+					Repositories teamRepositories `json:"repositories"`
+				} `json:"nodes"`
+				PageInfo pageInfo `json:"pageInfo"`
+			} `json:"teams"`
+		} `json:"organization"`
+	}
+
+	teamResponse struct {
+		Organization *struct {
+			Team *struct {
+				Repositories teamRepositories `json:"repositories"`
+			} `json:"team"`
+		} `json:"organization"`
+	}
+
+	ownerTypeResponse struct {
+		Repository *struct {
+			Owner struct {
+				Typename string `json:"__typename"`
+			} `json:"owner"`
+		} `json:"repository"`
+	}
+
+	collaboratorsResponse struct {
+		Repository *struct {
+			Collaborators struct {
+				Edges []struct {
+					Permission string `json:"permission"`
+					Node       struct {
+						Login string `json:"login"`
+					} `json:"node"`
+				} `json:"edges"`
+				PageInfo pageInfo `json:"pageInfo"`
+			} `json:"collaborators"`
+		} `json:"repository"`
+	}
+
+	membersResponse struct {
+		Organization *struct {
+			MembersWithRole struct {
+				Nodes []struct {
+					Login string `json:"login"`
+				} `json:"nodes"`
+				PageInfo pageInfo `json:"pageInfo"`
+			} `json:"membersWithRole"`
+		} `json:"organization"`
+	}
+)
+
+// GraphQL implements Backend using the GitHub GraphQL API. Teams are fetched together with their repository
+// permissions, and users are fetched in batches, so the number of API calls doesn't grow with the number of owners.
+// see: https://docs.github.com/en/graphql
+type GraphQL struct {
+	client   *http.Client
+	endpoint string
+	// rest resolves accounts which cannot be queried by GraphQL API, such as bots.
+	rest *REST
+
+	// mu guards the permissions, it's held while they are fetched, so concurrent calls don't fetch them again.
+	mu sync.Mutex
+	// permissions holds team permissions indexed by org/repo and lowercase team slug.
+	permissions map[string]map[string]Permission
+	// userPermissions holds collaborator permissions indexed by org/repo and lowercase login.
+	userPermissions map[string]map[string]Permission
+}
+
+// NewGraphQL returns a new GraphQL instance. The REST backend is used to resolve users missing
+// in the GraphQL API results. If nil, such users are reported as not existing.
+func NewGraphQL(client *http.Client, endpoint string, rest *REST) *GraphQL {
+	return &GraphQL{
+		client:          client,
+		endpoint:        endpoint,
+		rest:            rest,
+		permissions:     map[string]map[string]Permission{},
+		userPermissions: map[string]map[string]Permission{},
+	}
+}
+
+func (g *GraphQL) Scopes(ctx context.Context, org, repo string) ([]string, error) {
+	header, err := g.query(ctx, repositoryQuery, map[string]interface{}{"org": org, "repo": repo}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseScopes(header), nil
+}
+
+func (g *GraphQL) OwnerType(ctx context.Context, owner, repo string) (AccountType, error) {
+	var resp ownerTypeResponse
+	if _, err := g.query(ctx, ownerTypeQuery, map[string]interface{}{"owner": owner, "repo": repo}, &resp); err != nil {
+		return "", err
+	}
+	if resp.Repository == nil {
+		return "", notFoundError(fmt.Sprintf("repository %s/%s not found", owner, repo))
+	}
+	// the GraphQL type names match the REST API owner types
+	return AccountType(resp.Repository.Owner.Typename), nil
+}
+
+func (g *GraphQL) Teams(ctx context.Context, org string) ([]string, error) {
+	var slugs []string
+	err := g.listTeams(ctx, teamsQuery, map[string]interface{}{"org": org}, func(resp teamsResponse) {
+		for _, t := range resp.Organization.Teams.Nodes {
+			slugs = append(slugs, t.Slug)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slugs, nil
+}
+
+// TeamRepoPermission returns the permission of a given team. The permissions of all organization teams
+// to a given repository are fetched on the first call.
+func (g *GraphQL) TeamRepoPermission(ctx context.Context, org, slug, repo string) (Permission, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := strings.ToLower(org + "/" + repo)
+	perms, found := g.permissions[key]
+	if !found {
+		perms = map[string]Permission{}
+		// teams with more matching repositories than fit on the first page, indexed by slug with the next page cursor
+		remaining := map[string]string{}
+		err := g.listTeams(ctx, teamsPermissionsQuery, map[string]interface{}{"org": org, "repo": repo}, func(resp teamsResponse) {
+			for _, t := range resp.Organization.Teams.Nodes {
+				if perm, found := t.Repositories.permission(repo); found {
+					perms[strings.ToLower(t.Slug)] = perm
+				} else if t.Repositories.PageInfo.HasNextPage {
+					remaining[t.Slug] = t.Repositories.PageInfo.EndCursor
+				}
+			}
+		})
+		if err != nil {
+			return PermissionNone, err
+		}
+
+		for teamSlug, cursor := range remaining {
+			perm, err := g.teamRepoPermission(ctx, org, teamSlug, repo, cursor)
+			if err != nil {
+				return PermissionNone, err
+			}
+			perms[strings.ToLower(teamSlug)] = perm
+		}
+		g.permissions[key] = perms
+	}
+
+	return perms[strings.ToLower(slug)], nil
+}
+
+// teamRepoPermission pages through the team repositories matching a given name, starting from a given cursor.
+func (g *GraphQL) teamRepoPermission(ctx context.Context, org, slug, repo, cursor string) (Permission, error) {
+	vars := map[string]interface{}{"org": org, "slug": slug, "repo": repo, "cursor": cursor}
+	for {
+		var resp teamResponse
+		if _, err := g.query(ctx, teamRepositoriesQuery, vars, &resp); err != nil {
+			return PermissionNone, err
+		}
+		if resp.Organization == nil || resp.Organization.Team == nil {
+			return PermissionNone, notFoundError(fmt.Sprintf("team %s/%s not found", org, slug))
+		}
+
+		repos := resp.Organization.Team.Repositories
+		if perm, found := repos.permission(repo); found {
+			return perm, nil
+		}
+		if !repos.PageInfo.HasNextPage {
+			return PermissionNone, nil
+		}
+		vars["cursor"] = repos.PageInfo.EndCursor
+	}
+}
+
+// permission returns the permission to a given repository. The query argument matches
+// repositories by substring, so the name needs to be compared.
+func (r teamRepositories) permission(repo string) (Permission, bool) {
+	for _, e := range r.Edges {
+		if strings.EqualFold(e.Node.Name, repo) {
+			return graphQLPermissions[e.Permission], true
+		}
+	}
+	return PermissionNone, false
+}
+
+// UserRepoPermission returns the permission of a given user. The permissions of all repository collaborators
+// are fetched on the first call. Listing repository collaborators requires the push access to the repository.
+func (g *GraphQL) UserRepoPermission(ctx context.Context, org, repo, login string) (Permission, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := strings.ToLower(org + "/" + repo)
+	perms, found := g.userPermissions[key]
+	if !found {
+		var err error
+		perms, err = g.listCollaborators(ctx, org, repo)
+		if err != nil {
+			return PermissionNone, err
+		}
+		g.userPermissions[key] = perms
+	}
+
+	return perms[strings.ToLower(login)], nil
+}
+
+// listCollaborators returns the permissions of all repository collaborators indexed by lowercase login.
+func (g *GraphQL) listCollaborators(ctx context.Context, org, repo string) (map[string]Permission, error) {
+	var (
+		perms  = map[string]Permission{}
+		cursor interface{}
+	)
+	for {
+		var resp collaboratorsResponse
+		if _, err := g.query(ctx, collaboratorsQuery, map[string]interface{}{"org": org, "repo": repo, "cursor": cursor}, &resp); err != nil {
+			return nil, err
+		}
+		if resp.Repository == nil {
+			return nil, notFoundError(fmt.Sprintf("repository %s/%s not found", org, repo))
+		}
+
+		collaborators := resp.Repository.Collaborators
+		for _, e := range collaborators.Edges {
+			perms[strings.ToLower(e.Node.Login)] = graphQLPermissions[e.Permission]
+		}
+		if !collaborators.PageInfo.HasNextPage {
+			break
+		}
+		cursor = collaborators.PageInfo.EndCursor
+	}
+
+	return perms, nil
+}
+
+func (g *GraphQL) OrgMembers(ctx context.Context, org string) ([]string, error) {
+	var (
+		logins []string
+		cursor interface{}
+	)
+	for {
+		var resp membersResponse
+		if _, err := g.query(ctx, membersQuery, map[string]interface{}{"org": org, "cursor": cursor}, &resp); err != nil {
+			return nil, err
+		}
+		if resp.Organization == nil {
+			return nil, notFoundError(fmt.Sprintf("organization %q not found", org))
+		}
+
+		members := resp.Organization.MembersWithRole
+		for _, m := range members.Nodes {
+			logins = append(logins, m.Login)
+		}
+		if !members.PageInfo.HasNextPage {
+			break
+		}
+		cursor = members.PageInfo.EndCursor
+	}
+
+	return logins, nil
+}
+
+// Users fetches given users in batches, each user is queried under its own alias.
+// Accounts are resolved as repository owners, so organizations are found as well.
+// Bot accounts cannot be queried by login, so logins missing in the results are resolved via REST API.
+func (g *GraphQL) Users(ctx context.Context, logins []string) ([]User, error) {
+	var (
+		existing []User
+		missing  []string
+	)
+	for start := 0; start < len(logins); start += maxUsersPerQuery {
+		end := start + maxUsersPerQuery
+		if end > len(logins) {
+			end = len(logins)
+		}
+		batch := logins[start:end]
+
+		var (
+			params  []string
+			fields  []string
+			vars    = map[string]interface{}{}
+			results map[string]*struct {
+				Typename string `json:"__typename"`
+			}
+		)
+		for i, login := range batch {
+			params = append(params, fmt.Sprintf("$l%d: String!", i))
+			fields = append(fields, fmt.Sprintf("u%d: repositoryOwner(login: $l%d) { __typename }", i, i))
+			vars[fmt.Sprintf("l%d", i)] = login
+		}
+		q := fmt.Sprintf("query(%s) {\n  %s\n}", strings.Join(params, ", "), strings.Join(fields, "\n  "))
+
+		// logins without GitHub account are returned as null, possibly with the NOT_FOUND error
+		_, err := g.query(ctx, q, vars, &results)
+		var apiErr *APIError
+		if err != nil && !(errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound) {
+			return nil, err
+		}
+
+		for i, login := range batch {
+			// the GraphQL type names match the REST API account types
+			if owner := results[fmt.Sprintf("u%d", i)]; owner != nil {
+				existing = append(existing, User{Login: login, Type: AccountType(owner.Typename)})
+			} else {
+				missing = append(missing, login)
+			}
+		}
+	}
+
+	if g.rest == nil || len(missing) == 0 {
+		return existing, nil
+	}
+	resolved, err := g.rest.Users(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	return append(existing, resolved...), nil
+}
+
+func (g *GraphQL) listTeams(ctx context.Context, q string, vars map[string]interface{}, collect func(teamsResponse)) error {
+	for {
+		var resp teamsResponse
+		if _, err := g.query(ctx, q, vars, &resp); err != nil {
+			return err
+		}
+		if resp.Organization == nil {
+			return notFoundError(fmt.Sprintf("organization %q not found", vars["org"]))
+		}
+
+		collect(resp)
+
+		info := resp.Organization.Teams.PageInfo
+		if !info.HasNextPage {
+			return nil
+		}
+		vars["cursor"] = info.EndCursor
+	}
+}
+
+// query executes a given GraphQL query and decodes its data into out. The data is decoded
+// also when the response contains errors, as GraphQL API returns partial results.
+func (g *GraphQL) query(ctx context.Context, q string, vars map[string]interface{}, out interface{}) (http.Header, error) {
+	body, err := json.Marshal(graphQLRequest{Query: q, Variables: vars})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, &APIError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{
+			StatusCode:  resp.StatusCode,
+			RateLimited: isRateLimited(resp),
+			Err:         fmt.Errorf("POST %s: %s", g.endpoint, resp.Status),
+		}
+	}
+
+	var gqlResp graphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&gqlResp); err != nil {
+		return nil, &APIError{StatusCode: resp.StatusCode, Err: fmt.Errorf("while decoding GraphQL response: %w", err)}
+	}
+
+	if out != nil && len(gqlResp.Data) > 0 {
+		if err := json.Unmarshal(gqlResp.Data, out); err != nil {
+			return nil, &APIError{StatusCode: resp.StatusCode, Err: fmt.Errorf("while decoding GraphQL data: %w", err)}
+		}
+	}
+
+	return resp.Header, toGraphQLError(resp.StatusCode, gqlResp.Errors)
+}
+
+// toGraphQLError returns APIError describing given GraphQL errors, nil if there are no errors.
+// Errors which all are of the NOT_FOUND type are reported with the 404 status code.
+func toGraphQLError(statusCode int, errs []graphQLError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	var (
+		msgs        []string
+		notFound    = true
+		rateLimited = false
+	)
+	for _, e := range errs {
+		msgs = append(msgs, e.Message)
+		notFound = notFound && e.Type == graphQLNotFound
+		rateLimited = rateLimited || e.Type == graphQLRateLimited
+	}
+
+	err := &APIError{StatusCode: statusCode, RateLimited: rateLimited, Err: errors.New(strings.Join(msgs, "; "))}
+	if notFound {
+		err.StatusCode = http.StatusNotFound
+	}
+	return err
+}
+
+func notFoundError(msg string) *APIError {
+	return &APIError{StatusCode: http.StatusNotFound, Err: errors.New(msg)}
+}
+
+// isRateLimited returns true if the response was rejected because of the primary or secondary rate limit.
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	return resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != ""
+}
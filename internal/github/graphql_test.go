@@ -0,0 +1,370 @@
+package github_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.szostok.io/codeowners-validator/internal/github"
+
+	gh "github.com/google/go-github/v41/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLTeamRepoPermission(t *testing.T) {
+	// given
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		req := decodeRequest(t, r)
+		assert.Equal(t, "acme", req.Variables["org"])
+		assert.Equal(t, "app", req.Variables["repo"])
+
+		switch req.Variables["cursor"] {
+		case nil:
+			fmt.Fprint(w, `{"data": {"organization": {"teams": {
+				"nodes": [
+					{"slug": "Devs", "repositories": {"edges": [
+						{"permission": "READ", "node": {"name": "app-docs"}},
+						{"permission": "WRITE", "node": {"name": "app"}}
+					]}},
+					{"slug": "readers", "repositories": {"edges": [{"permission": "READ", "node": {"name": "app"}}]}}
+				],
+				"pageInfo": {"hasNextPage": true, "endCursor": "c1"}}}}}`)
+		case "c1":
+			fmt.Fprint(w, `{"data": {"organization": {"teams": {
+				"nodes": [{"slug": "others", "repositories": {"edges": []}}],
+				"pageInfo": {"hasNextPage": false, "endCursor": "c2"}}}}}`)
+		}
+	}))
+	defer srv.Close()
+
+	sut := github.NewGraphQL(srv.Client(), srv.URL, nil)
+
+	// when
+	devs, err := sut.TeamRepoPermission(context.Background(), "acme", "devs", "app")
+	require.NoError(t, err)
+	readers, err := sut.TeamRepoPermission(context.Background(), "acme", "readers", "app")
+	require.NoError(t, err)
+	others, err := sut.TeamRepoPermission(context.Background(), "acme", "others", "app")
+	require.NoError(t, err)
+
+	// then
+	assert.Equal(t, github.PermissionWrite, devs)
+	assert.Equal(t, github.PermissionRead, readers)
+	assert.Equal(t, github.PermissionNone, others)
+	assert.Equal(t, 2, calls, "permissions should be fetched only once")
+}
+
+func TestGraphQLTeamRepoPermissionPagination(t *testing.T) {
+	// given
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := decodeRequest(t, r)
+		assert.Equal(t, "api", req.Variables["repo"])
+
+		switch {
+		case req.Variables["slug"] == nil:
+			fmt.Fprint(w, `{"data": {"organization": {"teams": {
+				"nodes": [
+					{"slug": "platform", "repositories": {
+						"edges": [{"permission": "READ", "node": {"name": "api-docs"}}],
+						"pageInfo": {"hasNextPage": true, "endCursor": "r1"}}},
+					{"slug": "docs", "repositories": {
+						"edges": [{"permission": "READ", "node": {"name": "api-docs"}}],
+						"pageInfo": {"hasNextPage": true, "endCursor": "r1"}}}
+				],
+				"pageInfo": {"hasNextPage": false, "endCursor": "c1"}}}}}`)
+		case req.Variables["slug"] == "platform" && req.Variables["cursor"] == "r1":
+			fmt.Fprint(w, `{"data": {"organization": {"team": {"repositories": {
+				"edges": [{"permission": "MAINTAIN", "node": {"name": "api"}}],
+				"pageInfo": {"hasNextPage": true, "endCursor": "r2"}}}}}}`)
+		case req.Variables["slug"] == "docs" && req.Variables["cursor"] == "r1":
+			fmt.Fprint(w, `{"data": {"organization": {"team": {"repositories": {
+				"edges": [{"permission": "READ", "node": {"name": "api-gateway"}}],
+				"pageInfo": {"hasNextPage": false, "endCursor": "r2"}}}}}}`)
+		default:
+			t.Errorf("unexpected request: %v", req.Variables)
+		}
+	}))
+	defer srv.Close()
+
+	sut := github.NewGraphQL(srv.Client(), srv.URL, nil)
+
+	// when
+	platform, err := sut.TeamRepoPermission(context.Background(), "acme", "platform", "api")
+	require.NoError(t, err)
+	docs, err := sut.TeamRepoPermission(context.Background(), "acme", "docs", "api")
+	require.NoError(t, err)
+
+	// then
+	assert.Equal(t, github.PermissionMaintain, platform, "repository from the next page should be found")
+	assert.Equal(t, github.PermissionNone, docs)
+}
+
+func TestGraphQLOwnerType(t *testing.T) {
+	// given
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := decodeRequest(t, r)
+		assert.Equal(t, "app", req.Variables["repo"])
+
+		switch req.Variables["owner"] {
+		case "alice":
+			fmt.Fprint(w, `{"data": {"repository": {"owner": {"__typename": "User"}}}}`)
+		case "acme":
+			fmt.Fprint(w, `{"data": {"repository": {"owner": {"__typename": "Organization"}}}}`)
+		default:
+			fmt.Fprint(w, `{"data": {"repository": null}, "errors": [{"type": "NOT_FOUND", "message": "Could not resolve to a Repository"}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	sut := github.NewGraphQL(srv.Client(), srv.URL, nil)
+
+	// when
+	user, err := sut.OwnerType(context.Background(), "alice", "app")
+	require.NoError(t, err)
+	org, err := sut.OwnerType(context.Background(), "acme", "app")
+	require.NoError(t, err)
+	_, notFoundErr := sut.OwnerType(context.Background(), "unknown", "app")
+
+	// then
+	assert.Equal(t, github.UserAccount, user)
+	assert.Equal(t, github.OrganizationAccount, org)
+	var apiErr *github.APIError
+	require.ErrorAs(t, notFoundErr, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
+
+func TestGraphQLUserRepoPermission(t *testing.T) {
+	// given
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		req := decodeRequest(t, r)
+		assert.Equal(t, "acme", req.Variables["org"])
+		assert.Equal(t, "app", req.Variables["repo"])
+
+		switch req.Variables["cursor"] {
+		case nil:
+			fmt.Fprint(w, `{"data": {"repository": {"collaborators": {
+				"edges": [
+					{"permission": "ADMIN", "node": {"login": "alice-admin"}},
+					{"permission": "WRITE", "node": {"login": "carol"}}
+				],
+				"pageInfo": {"hasNextPage": true, "endCursor": "c1"}}}}}`)
+		case "c1":
+			fmt.Fprint(w, `{"data": {"repository": {"collaborators": {
+				"edges": [{"permission": "TRIAGE", "node": {"login": "Alice"}}],
+				"pageInfo": {"hasNextPage": false, "endCursor": "c2"}}}}}`)
+		}
+	}))
+	defer srv.Close()
+
+	sut := github.NewGraphQL(srv.Client(), srv.URL, nil)
+
+	// when
+	alice, err := sut.UserRepoPermission(context.Background(), "acme", "app", "alice")
+	require.NoError(t, err)
+	carol, err := sut.UserRepoPermission(context.Background(), "acme", "app", "carol")
+	require.NoError(t, err)
+	bob, err := sut.UserRepoPermission(context.Background(), "acme", "app", "bob")
+	require.NoError(t, err)
+
+	// then
+	assert.Equal(t, github.PermissionTriage, alice, "login should be matched exactly")
+	assert.Equal(t, github.PermissionWrite, carol)
+	assert.Equal(t, github.PermissionNone, bob)
+	assert.Equal(t, 2, calls, "collaborators should be fetched only once")
+}
+
+func TestGraphQLUsers(t *testing.T) {
+	// given
+	var batches []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := decodeRequest(t, r)
+		batches = append(batches, len(req.Variables))
+
+		data := map[string]interface{}{}
+		var errs []map[string]string
+		for i := 0; i < len(req.Variables); i++ {
+			login := req.Variables[fmt.Sprintf("l%d", i)].(string)
+			alias := fmt.Sprintf("u%d", i)
+			switch {
+			case strings.HasPrefix(login, "ghost"):
+				data[alias] = nil
+				errs = append(errs, map[string]string{"type": "NOT_FOUND", "message": fmt.Sprintf("Could not resolve to a RepositoryOwner with the login of '%s'.", login)})
+			case login == "acme":
+				data[alias] = map[string]string{"__typename": "Organization"}
+			default:
+				data[alias] = map[string]string{"__typename": "User"}
+			}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "errors": errs}))
+	}))
+	defer srv.Close()
+
+	var (
+		logins   []string
+		expUsers []github.User
+	)
+	for i := 0; i < 149; i++ {
+		logins = append(logins, fmt.Sprintf("user%d", i))
+		expUsers = append(expUsers, github.User{Login: fmt.Sprintf("user%d", i), Type: github.UserAccount})
+	}
+	logins = append(logins, "acme", "ghost1")
+	expUsers = append(expUsers, github.User{Login: "acme", Type: github.OrganizationAccount})
+
+	sut := github.NewGraphQL(srv.Client(), srv.URL, nil)
+
+	// when
+	existing, err := sut.Users(context.Background(), logins)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, expUsers, existing)
+	assert.Equal(t, []int{100, 51}, batches)
+}
+
+func TestGraphQLUsersResolvesMissingViaREST(t *testing.T) {
+	// given
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		req := decodeRequest(t, r)
+		data := map[string]interface{}{}
+		for i := 0; i < len(req.Variables); i++ {
+			alias := fmt.Sprintf("u%d", i)
+			if req.Variables[fmt.Sprintf("l%d", i)] == "alice" {
+				data[alias] = map[string]string{"__typename": "User"}
+				continue
+			}
+			data[alias] = nil
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{"data": data}))
+	})
+	mux.HandleFunc("/api/v3/users/renovate[bot]", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"login": "renovate[bot]", "type": "Bot"}`)
+	})
+	mux.HandleFunc("/api/v3/users/ghost", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := gh.NewEnterpriseClient(srv.URL, srv.URL, srv.Client())
+	require.NoError(t, err)
+	sut := github.NewGraphQL(srv.Client(), srv.URL+"/api/graphql", github.NewREST(client))
+
+	// when
+	existing, err := sut.Users(context.Background(), []string{"alice", "renovate[bot]", "ghost"})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []github.User{
+		{Login: "alice", Type: github.UserAccount},
+		{Login: "renovate[bot]", Type: github.BotAccount},
+	}, existing)
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	tests := map[string]struct {
+		handler        http.HandlerFunc
+		expStatusCode  int
+		expRateLimited bool
+		expErrMsg      string
+	}{
+		"Should report unauthorized request": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			expStatusCode: http.StatusUnauthorized,
+			expErrMsg:     "401 Unauthorized",
+		},
+		"Should report primary rate limit": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.WriteHeader(http.StatusForbidden)
+			},
+			expStatusCode:  http.StatusForbidden,
+			expRateLimited: true,
+			expErrMsg:      "403 Forbidden",
+		},
+		"Should report GraphQL rate limit": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(w, `{"data": null, "errors": [{"type": "RATE_LIMITED", "message": "API rate limit exceeded"}]}`)
+			},
+			expStatusCode:  http.StatusOK,
+			expRateLimited: true,
+			expErrMsg:      "API rate limit exceeded",
+		},
+		"Should report not found organization": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(w, `{"data": {"organization": null}, "errors": [{"type": "NOT_FOUND", "message": "Could not resolve to an Organization with the login of 'acme'."}]}`)
+			},
+			expStatusCode: http.StatusNotFound,
+			expErrMsg:     "Could not resolve to an Organization with the login of 'acme'.",
+		},
+	}
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			srv := httptest.NewServer(tc.handler)
+			defer srv.Close()
+
+			sut := github.NewGraphQL(srv.Client(), srv.URL, nil)
+
+			// when
+			_, err := sut.OrgMembers(context.Background(), "acme")
+
+			// then
+			var apiErr *github.APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tc.expStatusCode, apiErr.StatusCode)
+			assert.Equal(t, tc.expRateLimited, apiErr.RateLimited)
+			assert.Contains(t, apiErr.Error(), tc.expErrMsg)
+		})
+	}
+}
+
+func TestGraphQLEndpoint(t *testing.T) {
+	tests := map[string]struct {
+		baseURL     string
+		expEndpoint string
+	}{
+		"Public GitHub": {
+			baseURL:     "",
+			expEndpoint: "https://api.github.com/graphql",
+		},
+		"GitHub Enterprise host": {
+			baseURL:     "https://ghe.example.com",
+			expEndpoint: "https://ghe.example.com/api/graphql",
+		},
+		"GitHub Enterprise REST API": {
+			baseURL:     "https://ghe.example.com/api/v3/",
+			expEndpoint: "https://ghe.example.com/api/graphql",
+		},
+	}
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expEndpoint, github.GraphQLEndpoint(tc.baseURL))
+		})
+	}
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+func decodeRequest(t *testing.T, r *http.Request) graphQLRequest {
+	t.Helper()
+
+	var req graphQLRequest
+	require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+	return req
+}
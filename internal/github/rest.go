@@ -0,0 +1,180 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v41/github"
+)
+
+const scopeHeader = "X-OAuth-Scopes"
+
+// REST implements Backend using the GitHub REST API.
+type REST struct {
+	client *github.Client
+}
+
+// NewREST returns a new REST instance.
+func NewREST(client *github.Client) *REST {
+	return &REST{client: client}
+}
+
+func (r *REST) Scopes(ctx context.Context, org, repo string) ([]string, error) {
+	_, resp, err := r.client.Repositories.Get(ctx, org, repo)
+	if err != nil {
+		return nil, toAPIError(err)
+	}
+	return parseScopes(resp.Header), nil
+}
+
+func (r *REST) OwnerType(ctx context.Context, owner, repo string) (AccountType, error) {
+	repository, _, err := r.client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return "", toAPIError(err)
+	}
+	return AccountType(repository.GetOwner().GetType()), nil
+}
+
+func (r *REST) Teams(ctx context.Context, org string) ([]string, error) {
+	var slugs []string
+	req := &github.ListOptions{
+		PerPage: 100,
+	}
+	for {
+		resultPage, resp, err := r.client.Teams.ListTeams(ctx, org, req)
+		if err != nil {
+			return nil, toAPIError(err)
+		}
+		for _, t := range resultPage {
+			slugs = append(slugs, t.GetSlug())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		req.Page = resp.NextPage
+	}
+
+	return slugs, nil
+}
+
+func (r *REST) TeamRepoPermission(ctx context.Context, org, slug, repo string) (Permission, error) {
+	// repo contains the permissions for the team slug given
+	result, _, err := r.client.Teams.IsTeamRepoBySlug(ctx, org, slug, org, repo)
+	if err != nil {
+		apiErr := toAPIError(err)
+		if apiErr.StatusCode == http.StatusNotFound {
+			return PermissionNone, nil
+		}
+		return PermissionNone, apiErr
+	}
+
+	return highestPermission(result.GetPermissions()), nil
+}
+
+func (r *REST) UserRepoPermission(ctx context.Context, org, repo, login string) (Permission, error) {
+	level, _, err := r.client.Repositories.GetPermissionLevel(ctx, org, repo, login)
+	if err != nil {
+		apiErr := toAPIError(err)
+		if apiErr.StatusCode == http.StatusNotFound {
+			return PermissionNone, nil
+		}
+		return PermissionNone, apiErr
+	}
+
+	// the user permissions map distinguishes the triage and maintain roles,
+	// the legacy permission field reports them as read and write
+	if perm := highestPermission(level.GetUser().GetPermissions()); perm != PermissionNone {
+		return perm, nil
+	}
+	return legacyPermissions[level.GetPermission()], nil
+}
+
+// OrgMembers returns all organization members. There is a method to check if user is a org member
+//
+//	client.Organizations.IsMember(context.Background(), "org-name", "user-name")
+//
+// But latency is too huge for checking each single user independent
+// better and faster is to ask for all members and cache them.
+func (r *REST) OrgMembers(ctx context.Context, org string) ([]string, error) {
+	opt := &github.ListMembersOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var logins []string
+	for {
+		users, resp, err := r.client.Organizations.ListMembers(ctx, org, opt)
+		if err != nil {
+			return nil, toAPIError(err)
+		}
+		for _, u := range users {
+			logins = append(logins, u.GetLogin())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return logins, nil
+}
+
+func (r *REST) Users(ctx context.Context, logins []string) ([]User, error) {
+	var existing []User
+	for _, login := range logins {
+		user, _, err := r.client.Users.Get(ctx, login)
+		if err != nil {
+			apiErr := toAPIError(err)
+			if apiErr.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return nil, apiErr
+		}
+		existing = append(existing, User{Login: login, Type: AccountType(user.GetType())})
+	}
+	return existing, nil
+}
+
+// legacyPermissions maps the permission levels returned by the collaborator permission API.
+var legacyPermissions = map[string]Permission{
+	"read":  PermissionRead,
+	"write": PermissionWrite,
+	"admin": PermissionAdmin,
+}
+
+func parseScopes(header http.Header) []string {
+	var scopes []string
+	for _, scope := range strings.Split(header.Get(scopeHeader), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// toAPIError converts errors returned by the go-github client into APIError.
+func toAPIError(err error) *APIError {
+	var (
+		rateLimitErr *github.RateLimitError
+		abuseErr     *github.AbuseRateLimitError
+		respErr      *github.ErrorResponse
+	)
+	switch {
+	case errors.As(err, &rateLimitErr):
+		return &APIError{StatusCode: statusCode(rateLimitErr.Response), RateLimited: true, Err: errors.New(rateLimitErr.Message)}
+	case errors.As(err, &abuseErr):
+		return &APIError{StatusCode: statusCode(abuseErr.Response), RateLimited: true, Err: errors.New(abuseErr.Message)}
+	case errors.As(err, &respErr):
+		return &APIError{StatusCode: statusCode(respErr.Response), Err: err}
+	default:
+		return &APIError{Err: err}
+	}
+}
+
+func statusCode(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
@@ -0,0 +1,50 @@
+package github_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.szostok.io/codeowners-validator/internal/github"
+
+	gh "github.com/google/go-github/v41/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRESTUserRepoPermission(t *testing.T) {
+	// given
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/acme/app/collaborators/alice/permission", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"permission": "write", "user": {"login": "alice", "permissions": {"maintain": true, "push": true, "pull": true}}}`)
+	})
+	mux.HandleFunc("/api/v3/repos/acme/app/collaborators/bob/permission", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	})
+	mux.HandleFunc("/api/v3/repos/acme/app/collaborators/carol/permission", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := gh.NewEnterpriseClient(srv.URL, srv.URL, srv.Client())
+	require.NoError(t, err)
+	sut := github.NewREST(client)
+
+	// when
+	alice, err := sut.UserRepoPermission(context.Background(), "acme", "app", "alice")
+	require.NoError(t, err)
+	bob, err := sut.UserRepoPermission(context.Background(), "acme", "app", "bob")
+	require.NoError(t, err)
+	_, serverErr := sut.UserRepoPermission(context.Background(), "acme", "app", "carol")
+
+	// then
+	assert.Equal(t, github.PermissionMaintain, alice)
+	assert.Equal(t, github.PermissionNone, bob, "not found user should have no permissions")
+	var apiErr *github.APIError
+	require.ErrorAs(t, serverErr, &apiErr)
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+}
@@ -0,0 +1,405 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v41/github"
+	"github.com/pkg/errors"
+)
+
+// SnapshotVersion is the version of the snapshot file format.
+const SnapshotVersion = 1
+
+// ErrNotInSnapshot is returned by the Offline backend when the snapshot doesn't contain the requested data,
+// e.g. it was exported for another organization or without the validated repository.
+var ErrNotInSnapshot = errors.New("not present in snapshot")
+
+// Snapshot holds the organization data required to validate owners without calling GitHub.
+type Snapshot struct {
+	Version   int       `json:"version"`
+	Host      string    `json:"host"`
+	Org       string    `json:"org"`
+	CreatedAt time.Time `json:"createdAt"`
+	Members   []string  `json:"members"`
+	// OutsideCollaborators are users who have access to organization repositories, but are not its members.
+	OutsideCollaborators []string       `json:"outsideCollaborators"`
+	Teams                []SnapshotTeam `json:"teams"`
+	// Collaborators maps repository names to the effective permissions of users who can access them,
+	// including the permissions granted by teams and the organization base permission.
+	Collaborators map[string]map[string]Permission `json:"collaborators,omitempty"`
+	// Bots are the bot accounts found among the repository collaborators.
+	Bots []string `json:"bots,omitempty"`
+}
+
+// SnapshotTeam describes an organization team.
+type SnapshotTeam struct {
+	Slug string `json:"slug"`
+	// Parent is the slug of the parent team, empty for top-level teams.
+	Parent string `json:"parent,omitempty"`
+	// Repositories maps repository names to the permission of the team.
+	Repositories map[string]Permission `json:"repositories"`
+}
+
+// ExportSnapshot fetches the snapshot of a given organization. Listing outside collaborators
+// requires the organization owner permission. Collaborators are listed only for given repositories,
+// or for all organization repositories if none are given, which takes at least one API call per repository.
+func ExportSnapshot(ctx context.Context, client *github.Client, host, org string, repos []string) (*Snapshot, error) {
+	rest := NewREST(client)
+
+	members, err := rest.OrgMembers(ctx, org)
+	if err != nil {
+		return nil, errors.Wrap(err, "while listing organization members")
+	}
+
+	collaborators, err := listOutsideCollaborators(ctx, client, org)
+	if err != nil {
+		return nil, errors.Wrap(err, "while listing outside collaborators")
+	}
+
+	teams, err := listSnapshotTeams(ctx, client, org)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(repos) == 0 {
+		repos, err = listOrgRepositories(ctx, client, org)
+		if err != nil {
+			return nil, errors.Wrap(err, "while listing repositories")
+		}
+	}
+
+	repoCollaborators, bots, err := listSnapshotCollaborators(ctx, client, org, repos)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		Version:              SnapshotVersion,
+		Host:                 host,
+		Org:                  org,
+		CreatedAt:            time.Now().UTC(),
+		Members:              members,
+		OutsideCollaborators: collaborators,
+		Teams:                teams,
+		Collaborators:        repoCollaborators,
+		Bots:                 bots,
+	}, nil
+}
+
+// LoadSnapshot reads the snapshot from a given file.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "while reading snapshot")
+	}
+
+	var s Snapshot
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, errors.Wrapf(err, "while parsing snapshot %s", path)
+	}
+	if s.Version != SnapshotVersion {
+		return nil, fmt.Errorf("not supported snapshot version %d, expected %d", s.Version, SnapshotVersion)
+	}
+	return &s, nil
+}
+
+func listOutsideCollaborators(ctx context.Context, client *github.Client, org string) ([]string, error) {
+	opt := &github.ListOutsideCollaboratorsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var logins []string
+	for {
+		users, resp, err := client.Organizations.ListOutsideCollaborators(ctx, org, opt)
+		if err != nil {
+			return nil, toAPIError(err)
+		}
+		for _, u := range users {
+			logins = append(logins, u.GetLogin())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return logins, nil
+}
+
+func listSnapshotTeams(ctx context.Context, client *github.Client, org string) ([]SnapshotTeam, error) {
+	var teams []SnapshotTeam
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := client.Teams.ListTeams(ctx, org, opt)
+		if err != nil {
+			return nil, errors.Wrap(toAPIError(err), "while listing teams")
+		}
+		for _, t := range page {
+			repos, err := listTeamRepositories(ctx, client, org, t.GetSlug())
+			if err != nil {
+				return nil, errors.Wrapf(err, "while listing repositories of team %q", t.GetSlug())
+			}
+			teams = append(teams, SnapshotTeam{
+				Slug:         t.GetSlug(),
+				Parent:       t.GetParent().GetSlug(),
+				Repositories: repos,
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return teams, nil
+}
+
+func listTeamRepositories(ctx context.Context, client *github.Client, org, slug string) (map[string]Permission, error) {
+	repos := map[string]Permission{}
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := client.Teams.ListTeamReposBySlug(ctx, org, slug, opt)
+		if err != nil {
+			return nil, toAPIError(err)
+		}
+		for _, r := range page {
+			repos[r.GetName()] = highestPermission(r.GetPermissions())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return repos, nil
+}
+
+func listOrgRepositories(ctx context.Context, client *github.Client, org string) ([]string, error) {
+	var names []string
+	opt := &github.RepositoryListByOrgOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := client.Repositories.ListByOrg(ctx, org, opt)
+		if err != nil {
+			return nil, toAPIError(err)
+		}
+		for _, r := range page {
+			names = append(names, r.GetName())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return names, nil
+}
+
+// listSnapshotCollaborators returns the collaborators of given repositories, and the logins of bot accounts among them.
+func listSnapshotCollaborators(ctx context.Context, client *github.Client, org string, repos []string) (map[string]map[string]Permission, []string, error) {
+	var (
+		out  = map[string]map[string]Permission{}
+		bots []string
+		seen = map[string]struct{}{}
+	)
+	for _, repo := range repos {
+		users, err := listRepoCollaborators(ctx, client, org, repo)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "while listing collaborators of repository %q", repo)
+		}
+
+		perms := map[string]Permission{}
+		for _, u := range users {
+			perms[u.GetLogin()] = highestPermission(u.GetPermissions())
+			if _, found := seen[u.GetLogin()]; !found && AccountType(u.GetType()) == BotAccount {
+				seen[u.GetLogin()] = struct{}{}
+				bots = append(bots, u.GetLogin())
+			}
+		}
+		out[repo] = perms
+	}
+
+	return out, bots, nil
+}
+
+func listRepoCollaborators(ctx context.Context, client *github.Client, org, repo string) ([]*github.User, error) {
+	var users []*github.User
+	opt := &github.ListCollaboratorsOptions{
+		Affiliation: "all",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		page, resp, err := client.Repositories.ListCollaborators(ctx, org, repo, opt)
+		if err != nil {
+			return nil, toAPIError(err)
+		}
+		users = append(users, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return users, nil
+}
+
+// permissionsByRank lists permissions from the highest one.
+var permissionsByRank = []Permission{PermissionAdmin, PermissionMaintain, PermissionWrite, PermissionTriage, PermissionRead}
+
+// highestPermission returns the highest permission set in the REST API permissions map.
+func highestPermission(perms map[string]bool) Permission {
+	for _, p := range permissionsByRank {
+		if perms[string(p)] {
+			return p
+		}
+	}
+	return PermissionNone
+}
+
+// higher returns the higher of given permissions.
+func higher(a, b Permission) Permission {
+	for _, p := range permissionsByRank {
+		if a == p || b == p {
+			return p
+		}
+	}
+	return PermissionNone
+}
+
+// Offline implements Backend using the organization snapshot, so owners are validated without calling GitHub.
+// The snapshot doesn't contain all GitHub accounts, so the existence and type of accounts outside the organization
+// cannot be verified. All of them are assumed to be existing users, so owners without a GitHub account and
+// other organizations are reported as users who are neither members nor collaborators. Only the snapshot
+// organization and bots which are repository collaborators are recognized as accounts which cannot review PRs.
+type Offline struct {
+	snapshot *Snapshot
+	teams    map[string]SnapshotTeam
+}
+
+// NewOffline returns a new Offline instance.
+func NewOffline(snapshot *Snapshot) *Offline {
+	teams := map[string]SnapshotTeam{}
+	for _, t := range snapshot.Teams {
+		teams[strings.ToLower(t.Slug)] = t
+	}
+	return &Offline{snapshot: snapshot, teams: teams}
+}
+
+// Scopes returns no scopes, as there is no token. It returns an error if the repository organization
+// is not the snapshot one.
+func (o *Offline) Scopes(_ context.Context, org, _ string) ([]string, error) {
+	if err := o.checkOrg(org); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// OwnerType returns OrganizationAccount, as snapshots are exported only for organizations.
+func (o *Offline) OwnerType(_ context.Context, owner, _ string) (AccountType, error) {
+	if err := o.checkOrg(owner); err != nil {
+		return "", err
+	}
+	return OrganizationAccount, nil
+}
+
+func (o *Offline) Teams(_ context.Context, org string) ([]string, error) {
+	if err := o.checkOrg(org); err != nil {
+		return nil, err
+	}
+
+	slugs := make([]string, 0, len(o.snapshot.Teams))
+	for _, t := range o.snapshot.Teams {
+		slugs = append(slugs, t.Slug)
+	}
+	return slugs, nil
+}
+
+// TeamRepoPermission returns the highest permission of a given team and its parent teams,
+// as child teams inherit the permissions of their parents.
+func (o *Offline) TeamRepoPermission(_ context.Context, org, slug, repo string) (Permission, error) {
+	if err := o.checkOrg(org); err != nil {
+		return PermissionNone, err
+	}
+
+	perm := PermissionNone
+	visited := map[string]struct{}{}
+	for team, found := o.teams[strings.ToLower(slug)]; found; team, found = o.teams[strings.ToLower(team.Parent)] {
+		if _, cycle := visited[team.Slug]; cycle {
+			break
+		}
+		visited[team.Slug] = struct{}{}
+
+		for name, p := range team.Repositories {
+			if strings.EqualFold(name, repo) {
+				perm = higher(perm, p)
+			}
+		}
+	}
+	return perm, nil
+}
+
+// UserRepoPermission returns the permission of a given user. It returns ErrNotInSnapshot if the snapshot
+// doesn't contain the collaborators of a given repository.
+func (o *Offline) UserRepoPermission(_ context.Context, org, repo, login string) (Permission, error) {
+	if err := o.checkOrg(org); err != nil {
+		return PermissionNone, err
+	}
+
+	for name, perms := range o.snapshot.Collaborators {
+		if !strings.EqualFold(name, repo) {
+			continue
+		}
+		for user, p := range perms {
+			if strings.EqualFold(user, login) {
+				return p, nil
+			}
+		}
+		return PermissionNone, nil
+	}
+
+	return PermissionNone, fmt.Errorf("repository %q %w", repo, ErrNotInSnapshot)
+}
+
+func (o *Offline) OrgMembers(_ context.Context, org string) ([]string, error) {
+	if err := o.checkOrg(org); err != nil {
+		return nil, err
+	}
+	return o.snapshot.Members, nil
+}
+
+// Users returns all given logins, as their existence cannot be verified offline. Only the snapshot organization
+// and the snapshot bots are recognized as organization and bot accounts.
+func (o *Offline) Users(_ context.Context, logins []string) ([]User, error) {
+	users := make([]User, 0, len(logins))
+	for _, login := range logins {
+		accountType := UserAccount
+		switch {
+		case strings.EqualFold(login, o.snapshot.Org):
+			accountType = OrganizationAccount
+		case containsFold(o.snapshot.Bots, login):
+			accountType = BotAccount
+		}
+		users = append(users, User{Login: login, Type: accountType})
+	}
+	return users, nil
+}
+
+func (o *Offline) checkOrg(org string) error {
+	if !strings.EqualFold(org, o.snapshot.Org) {
+		return fmt.Errorf("organization %q %w of the %q organization", org, ErrNotInSnapshot, o.snapshot.Org)
+	}
+	return nil
+}
+
+func containsFold(logins []string, login string) bool {
+	for _, l := range logins {
+		if strings.EqualFold(l, login) {
+			return true
+		}
+	}
+	return false
+}
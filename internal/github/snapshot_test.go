@@ -0,0 +1,199 @@
+package github_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.szostok.io/codeowners-validator/internal/github"
+
+	gh "github.com/google/go-github/v41/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffline(t *testing.T) {
+	// given
+	ctx := context.Background()
+	sut := github.NewOffline(&github.Snapshot{
+		Version: github.SnapshotVersion,
+		Org:     "Acme",
+		Members: []string{"member"},
+		Teams: []github.SnapshotTeam{
+			{Slug: "eng", Repositories: map[string]github.Permission{"app": github.PermissionWrite}},
+			{Slug: "backend", Parent: "eng", Repositories: map[string]github.Permission{"App": github.PermissionRead, "api": github.PermissionAdmin}},
+			{Slug: "docs", Repositories: map[string]github.Permission{"app": github.PermissionTriage}},
+		},
+		Collaborators: map[string]map[string]github.Permission{
+			"App": {"Member": github.PermissionWrite, "ci-bot": github.PermissionWrite},
+		},
+		Bots: []string{"ci-bot"},
+	})
+
+	// when
+	ownerType, err := sut.OwnerType(ctx, "acme", "app")
+	require.NoError(t, err)
+	teams, err := sut.Teams(ctx, "acme")
+	require.NoError(t, err)
+	inherited, err := sut.TeamRepoPermission(ctx, "acme", "Backend", "app")
+	require.NoError(t, err)
+	own, err := sut.TeamRepoPermission(ctx, "acme", "docs", "app")
+	require.NoError(t, err)
+	none, err := sut.TeamRepoPermission(ctx, "acme", "docs", "api")
+	require.NoError(t, err)
+	users, err := sut.Users(ctx, []string{"member", "outsider", "acme", "CI-Bot"})
+	require.NoError(t, err)
+	userPerm, err := sut.UserRepoPermission(ctx, "acme", "app", "member")
+	require.NoError(t, err)
+	noUserPerm, err := sut.UserRepoPermission(ctx, "acme", "app", "outsider")
+	require.NoError(t, err)
+	_, missingRepoErr := sut.UserRepoPermission(ctx, "acme", "api", "member")
+	_, otherOrgErr := sut.OrgMembers(ctx, "other")
+
+	// then
+	assert.Equal(t, github.OrganizationAccount, ownerType)
+	assert.Equal(t, []string{"eng", "backend", "docs"}, teams)
+	assert.Equal(t, github.PermissionWrite, inherited, "permission should be inherited from the parent team")
+	assert.Equal(t, github.PermissionTriage, own)
+	assert.Equal(t, github.PermissionNone, none)
+	assert.Equal(t, []github.User{
+		{Login: "member", Type: github.UserAccount},
+		{Login: "outsider", Type: github.UserAccount},
+		{Login: "acme", Type: github.OrganizationAccount},
+		{Login: "CI-Bot", Type: github.BotAccount},
+	}, users, "users existence cannot be verified offline")
+	assert.Equal(t, github.PermissionWrite, userPerm)
+	assert.Equal(t, github.PermissionNone, noUserPerm)
+	assert.EqualError(t, missingRepoErr, `repository "api" not present in snapshot`)
+	assert.ErrorIs(t, missingRepoErr, github.ErrNotInSnapshot)
+	assert.EqualError(t, otherOrgErr, `organization "other" not present in snapshot of the "Acme" organization`)
+	assert.ErrorIs(t, otherOrgErr, github.ErrNotInSnapshot)
+}
+
+func TestExportSnapshot(t *testing.T) {
+	// given
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/orgs/acme/members", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"login": "alice"}, {"login": "bob"}]`)
+	})
+	mux.HandleFunc("/api/v3/orgs/acme/outside_collaborators", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"login": "carol"}]`)
+	})
+	mux.HandleFunc("/api/v3/orgs/acme/teams", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"slug": "eng"}, {"slug": "backend", "parent": {"slug": "eng"}}]`)
+	})
+	mux.HandleFunc("/api/v3/orgs/acme/teams/eng/repos", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"name": "app", "permissions": {"admin": false, "push": true, "pull": true}}]`)
+	})
+	mux.HandleFunc("/api/v3/orgs/acme/teams/backend/repos", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"name": "api", "permissions": {"admin": true, "push": true, "pull": true}}]`)
+	})
+	mux.HandleFunc("/api/v3/orgs/acme/repos", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"name": "app"}]`)
+	})
+	mux.HandleFunc("/api/v3/repos/acme/app/collaborators", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "all", r.URL.Query().Get("affiliation"))
+		fmt.Fprint(w, `[
+			{"login": "alice", "permissions": {"admin": false, "maintain": true, "push": true, "triage": true, "pull": true}},
+			{"login": "carol", "permissions": {"admin": false, "maintain": false, "push": false, "triage": true, "pull": true}},
+			{"login": "ci-bot", "type": "Bot", "permissions": {"admin": false, "maintain": false, "push": true, "triage": true, "pull": true}}
+		]`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := gh.NewEnterpriseClient(srv.URL, srv.URL, srv.Client())
+	require.NoError(t, err)
+
+	// when
+	snapshot, err := github.ExportSnapshot(context.Background(), client, "ghe.example.com", "acme", nil)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, github.SnapshotVersion, snapshot.Version)
+	assert.Equal(t, "ghe.example.com", snapshot.Host)
+	assert.Equal(t, "acme", snapshot.Org)
+	assert.Equal(t, []string{"alice", "bob"}, snapshot.Members)
+	assert.Equal(t, []string{"carol"}, snapshot.OutsideCollaborators)
+	assert.Equal(t, []github.SnapshotTeam{
+		{Slug: "eng", Repositories: map[string]github.Permission{"app": github.PermissionWrite}},
+		{Slug: "backend", Parent: "eng", Repositories: map[string]github.Permission{"api": github.PermissionAdmin}},
+	}, snapshot.Teams)
+	assert.Equal(t, map[string]map[string]github.Permission{
+		"app": {"alice": github.PermissionMaintain, "carol": github.PermissionTriage, "ci-bot": github.PermissionWrite},
+	}, snapshot.Collaborators)
+	assert.Equal(t, []string{"ci-bot"}, snapshot.Bots)
+}
+
+func TestExportSnapshotRepositories(t *testing.T) {
+	// given
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/orgs/acme/members", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"login": "alice"}]`)
+	})
+	mux.HandleFunc("/api/v3/orgs/acme/outside_collaborators", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/api/v3/orgs/acme/teams", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/api/v3/orgs/acme/repos", func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("organization repositories should not be listed")
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/api/v3/repos/acme/app/collaborators", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"login": "alice", "permissions": {"push": true, "pull": true}}]`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := gh.NewEnterpriseClient(srv.URL, srv.URL, srv.Client())
+	require.NoError(t, err)
+
+	// when
+	snapshot, err := github.ExportSnapshot(context.Background(), client, "ghe.example.com", "acme", []string{"app"})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]github.Permission{
+		"app": {"alice": github.PermissionWrite},
+	}, snapshot.Collaborators, "only collaborators of given repositories should be exported")
+}
+
+func TestLoadSnapshot(t *testing.T) {
+	tests := map[string]struct {
+		content   string
+		expErrMsg string
+	}{
+		"Should load snapshot": {
+			content: `{"version": 1, "org": "acme", "members": ["alice"]}`,
+		},
+		"Should reject not supported version": {
+			content:   `{"version": 2, "org": "acme"}`,
+			expErrMsg: "not supported snapshot version 2, expected 1",
+		},
+	}
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			path := filepath.Join(t.TempDir(), "snapshot.json")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			// when
+			snapshot, err := github.LoadSnapshot(path)
+
+			// then
+			if tc.expErrMsg != "" {
+				assert.EqualError(t, err, tc.expErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "acme", snapshot.Org)
+			assert.Equal(t, []string{"alice"}, snapshot.Members)
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 30 * time.Second
+
+	// secondaryRateLimitWait is used when GitHub doesn't tell how long to wait after hitting the secondary rate limit.
+	// see: https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api#exceeding-the-rate-limit
+	secondaryRateLimitWait = time.Minute
+	// maxPeekedBody limits the size of the response body read to detect the secondary rate limit.
+	maxPeekedBody = 4 << 10
+)
+
+// retryTransport retries GitHub API requests which failed because of transient server and network errors,
+// or because the rate limit was reached. Each attempt gets its own timeout, so waiting doesn't count towards it.
+type retryTransport struct {
+	next           http.RoundTripper
+	maxRetries     int
+	attemptTimeout time.Duration
+	sleep          func(context.Context, time.Duration) error
+	now            func() time.Time
+
+	mu sync.Mutex
+	// rateLimitBudget is the remaining wall-clock time which can be spent waiting for rate limit resets.
+	rateLimitBudget time.Duration
+	// rateLimitedUntil is the end of the latest rate limit wait. Shorter waits overlap with it, so they are free.
+	rateLimitedUntil time.Time
+}
+
+func newRetryTransport(next http.RoundTripper, cfg *ClientConfig) *retryTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &retryTransport{
+		next:            next,
+		maxRetries:      cfg.MaxRetries,
+		attemptTimeout:  cfg.HTTPRequestTimeout,
+		rateLimitBudget: cfg.RateLimitWaitBudget,
+		sleep:           sleepCtx,
+		now:             time.Now,
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	retries := 0
+	for attempt := 0; ; attempt++ {
+		resp, err := t.attempt(req, attempt == 0)
+		if ctx.Err() != nil {
+			return resp, err
+		}
+
+		if err == nil {
+			if wait, limited := t.rateLimitWait(resp); limited {
+				// waiting at least a second keeps consuming the budget, so the retries eventually stop
+				if wait < time.Second {
+					wait = time.Second
+				}
+				if !t.reserveRateLimitWait(wait) || !canRetry(req) {
+					return resp, nil
+				}
+				discard(resp)
+				if err := t.sleep(ctx, wait); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		if !isTransient(resp, err) || retries >= t.maxRetries || !canRetry(req) {
+			return resp, err
+		}
+		if resp != nil {
+			discard(resp)
+		}
+		if err := t.sleep(ctx, backoff(retries)); err != nil {
+			return nil, err
+		}
+		retries++
+	}
+}
+
+// attempt sends a given request once. The attempt timeout is canceled when the response body is closed.
+// The original request body is sent in the first attempt, and its copy in the following ones.
+func (t *retryTransport) attempt(req *http.Request, first bool) (*http.Response, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.attemptTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.attemptTimeout)
+	}
+
+	attemptReq := req.Clone(ctx)
+	if !first && req.Body != nil && req.Body != http.NoBody {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		attemptReq.Body = body
+	}
+
+	resp, err := t.next.RoundTrip(attemptReq)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// rateLimitWait returns how long to wait before retrying a request rejected because of the rate limit.
+func (t *retryTransport) rateLimitWait(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	if wait, found := t.retryAfter(resp.Header); found {
+		return wait, true
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		wait := time.Unix(reset, 0).Sub(t.now())
+		if wait < 0 {
+			wait = 0
+		}
+		// the reset time has a second precision
+		return wait + time.Second, true
+	}
+
+	if isSecondaryRateLimit(resp) {
+		return secondaryRateLimitWait, true
+	}
+
+	return 0, false
+}
+
+// retryAfter parses the Retry-After header, which holds either seconds or an HTTP date.
+func (t *retryTransport) retryAfter(header http.Header) (time.Duration, bool) {
+	val := header.Get("Retry-After")
+	if val == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(val); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	date, err := http.ParseTime(val)
+	if err != nil {
+		return 0, false
+	}
+	wait := date.Sub(t.now())
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
+
+// reserveRateLimitWait returns true if a given wait fits into the remaining budget.
+// Concurrent waits overlap, so only the time by which a wait outlasts the ongoing ones is subtracted from the budget.
+func (t *retryTransport) reserveRateLimitWait(wait time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	until := now.Add(wait)
+	if !until.After(t.rateLimitedUntil) {
+		return true
+	}
+
+	waitingFrom := now
+	if t.rateLimitedUntil.After(now) {
+		waitingFrom = t.rateLimitedUntil
+	}
+	extension := until.Sub(waitingFrom)
+	if extension > t.rateLimitBudget {
+		return false
+	}
+	t.rateLimitBudget -= extension
+	t.rateLimitedUntil = until
+	return true
+}
+
+// isSecondaryRateLimit checks if the response body mentions the secondary rate limit.
+// The read part of the body is put back, so the response can be still processed by the caller.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+
+	peeked, err := io.ReadAll(io.LimitReader(resp.Body, maxPeekedBody))
+	resp.Body = &multiReadCloser{Reader: io.MultiReader(bytes.NewReader(peeked), resp.Body), Closer: resp.Body}
+	if err != nil {
+		return false
+	}
+
+	return strings.Contains(strings.ToLower(string(peeked)), "secondary rate limit")
+}
+
+// isTransient returns true for network errors and server errors which may succeed when retried.
+func isTransient(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// canRetry returns true if the request body can be sent again.
+func canRetry(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func backoff(retry int) time.Duration {
+	wait := initialBackoff << retry
+	if wait > maxBackoff || wait <= 0 {
+		return maxBackoff
+	}
+	return wait
+}
+
+// discard drains and closes the response body, so the connection can be reused.
+func discard(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxPeekedBody))
+	_ = resp.Body.Close()
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,263 @@
+package github_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.szostok.io/codeowners-validator/internal/github"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryTransport(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	reset := strconv.FormatInt(now.Add(10*time.Second).Unix(), 10)
+
+	type response struct {
+		status int
+		header map[string]string
+		body   string
+	}
+	tests := map[string]struct {
+		responses   []response
+		budget      time.Duration
+		expStatus   int
+		expBody     string
+		expCalls    int
+		expSleeps   []time.Duration
+		expReqBody  string
+		requestBody string
+	}{
+		"Should retry server errors with exponential backoff": {
+			responses: []response{
+				{status: http.StatusBadGateway},
+				{status: http.StatusServiceUnavailable},
+				{status: http.StatusOK, body: "ok"},
+			},
+			expStatus: http.StatusOK,
+			expBody:   "ok",
+			expCalls:  3,
+			expSleeps: []time.Duration{500 * time.Millisecond, time.Second},
+		},
+		"Should return the last server error when retries are exhausted": {
+			responses: []response{
+				{status: http.StatusInternalServerError},
+				{status: http.StatusInternalServerError},
+				{status: http.StatusInternalServerError},
+				{status: http.StatusInternalServerError, body: "failed"},
+			},
+			expStatus: http.StatusInternalServerError,
+			expBody:   "failed",
+			expCalls:  4,
+			expSleeps: []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second},
+		},
+		"Should not retry client errors": {
+			responses: []response{
+				{status: http.StatusNotFound, body: "not found"},
+			},
+			expStatus: http.StatusNotFound,
+			expBody:   "not found",
+			expCalls:  1,
+		},
+		"Should wait until the primary rate limit reset": {
+			responses: []response{
+				{status: http.StatusForbidden, header: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset}},
+				{status: http.StatusOK, body: "ok"},
+			},
+			budget:    time.Minute,
+			expStatus: http.StatusOK,
+			expBody:   "ok",
+			expCalls:  2,
+			expSleeps: []time.Duration{11 * time.Second},
+		},
+		"Should honor the Retry-After header": {
+			responses: []response{
+				{status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "5"}},
+				{status: http.StatusOK, body: "ok"},
+			},
+			budget:    time.Minute,
+			expStatus: http.StatusOK,
+			expBody:   "ok",
+			expCalls:  2,
+			expSleeps: []time.Duration{5 * time.Second},
+		},
+		"Should wait after the secondary rate limit without Retry-After header": {
+			responses: []response{
+				{status: http.StatusForbidden, body: `{"message": "You have exceeded a secondary rate limit."}`},
+				{status: http.StatusOK, body: "ok"},
+			},
+			budget:    2 * time.Minute,
+			expStatus: http.StatusOK,
+			expBody:   "ok",
+			expCalls:  2,
+			expSleeps: []time.Duration{time.Minute},
+		},
+		"Should return the rate limit response when the wait exceeds the budget": {
+			responses: []response{
+				{status: http.StatusForbidden, body: `{"message": "You have exceeded a secondary rate limit."}`},
+			},
+			budget:    30 * time.Second,
+			expStatus: http.StatusForbidden,
+			expBody:   `{"message": "You have exceeded a secondary rate limit."}`,
+			expCalls:  1,
+		},
+		"Should share the budget between rate limit waits": {
+			responses: []response{
+				{status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "20"}},
+				{status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "20"}},
+				{status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "20"}},
+			},
+			budget:    45 * time.Second,
+			expStatus: http.StatusTooManyRequests,
+			expCalls:  3,
+			expSleeps: []time.Duration{20 * time.Second, 20 * time.Second},
+		},
+		"Should resend the request body": {
+			responses: []response{
+				{status: http.StatusBadGateway},
+				{status: http.StatusOK, body: "ok"},
+			},
+			requestBody: `{"query": "{ viewer { login } }"}`,
+			expStatus:   http.StatusOK,
+			expBody:     "ok",
+			expCalls:    2,
+			expSleeps:   []time.Duration{500 * time.Millisecond},
+			expReqBody:  `{"query": "{ viewer { login } }"}`,
+		},
+	}
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, tc.requestBody, string(body))
+
+				resp := tc.responses[calls]
+				calls++
+				for k, v := range resp.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(resp.status)
+				_, _ = io.WriteString(w, resp.body)
+			}))
+			defer srv.Close()
+
+			clock := now
+			var sleeps []time.Duration
+			sleep := func(_ context.Context, d time.Duration) error {
+				sleeps = append(sleeps, d)
+				clock = clock.Add(d)
+				return nil
+			}
+			cfg := &github.ClientConfig{MaxRetries: 3, RateLimitWaitBudget: tc.budget, HTTPRequestTimeout: time.Minute}
+			client := &http.Client{Transport: github.NewRetryTransport(srv.Client().Transport, cfg, sleep, func() time.Time { return clock })}
+
+			var reqBody io.Reader
+			if tc.requestBody != "" {
+				reqBody = strings.NewReader(tc.requestBody)
+			}
+			req, err := http.NewRequest(http.MethodPost, srv.URL, reqBody)
+			require.NoError(t, err)
+
+			// when
+			resp, err := client.Do(req)
+
+			// then
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expStatus, resp.StatusCode)
+			assert.Equal(t, tc.expBody, string(body))
+			assert.Equal(t, tc.expCalls, calls)
+			assert.Equal(t, tc.expSleeps, sleeps)
+		})
+	}
+}
+
+func TestRetryTransportConcurrentRateLimitWaits(t *testing.T) {
+	// given
+	const requests = 10
+	var (
+		mu      sync.Mutex
+		limited = map[string]bool{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !limited[r.URL.Path] {
+			limited[r.URL.Path] = true
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// all requests wait for the same reset, so the clock doesn't move in the meantime
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sleep := func(context.Context, time.Duration) error { return nil }
+	cfg := &github.ClientConfig{RateLimitWaitBudget: 40 * time.Second, HTTPRequestTimeout: time.Minute}
+	client := &http.Client{Transport: github.NewRetryTransport(srv.Client().Transport, cfg, sleep, func() time.Time { return now })}
+
+	// when
+	statuses := make([]int, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := client.Get(fmt.Sprintf("%s/%d", srv.URL, i))
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+			statuses[i] = resp.StatusCode
+		}(i)
+	}
+	wg.Wait()
+
+	// then
+	for i, status := range statuses {
+		assert.Equal(t, http.StatusOK, status, "request %d", i)
+	}
+}
+
+func TestRetryTransportCanceledContext(t *testing.T) {
+	// given
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sleep := func(context.Context, time.Duration) error {
+		cancel()
+		return context.Canceled
+	}
+	cfg := &github.ClientConfig{MaxRetries: 3}
+	client := &http.Client{Transport: github.NewRetryTransport(srv.Client().Transport, cfg, sleep, time.Now)}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	// when
+	_, err = client.Do(req)
+
+	// then
+	assert.ErrorIs(t, err, context.Canceled)
+}
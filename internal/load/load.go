@@ -2,98 +2,150 @@ package load
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"go.szostok.io/codeowners-validator/internal/check"
-	"go.szostok.io/codeowners-validator/internal/envconfig"
-	"go.szostok.io/codeowners-validator/internal/github"
-
-	"github.com/pkg/errors"
+	"go.szostok.io/codeowners-validator/internal/config"
 )
 
-// For now, it is a good enough solution to init checks. Important thing is to do not require env variables
-// and do not create clients which will not be used because of the given checker.
-//
-// MAYBE in the future the https://github.com/uber-go/dig will be used.
-func Checks(ctx context.Context, enabledChecks, experimentalChecks []string) ([]check.Checker, error) {
-	var checks []check.Checker
+// Selection holds checks selected by a user.
+type Selection struct {
+	// Checks replaces the default set of checks. By default, all stable checks are executed.
+	Checks []string
+	// Experimental is appended to the selected checks. Kept for backward compatibility, use Enable instead.
+	Experimental []string
+	// Enable is appended to the selected checks.
+	Enable []string
+	// Disable is removed from the selected checks.
+	Disable []string
+}
 
-	if isEnabled(enabledChecks, "syntax") {
-		checks = append(checks, check.NewValidSyntax())
+// Configs returns the configuration structs of all checks, e.g. to expose them as CLI flags.
+func Configs() []interface{} {
+	var out []interface{}
+	for _, def := range Registry() {
+		if def.Config != nil {
+			out = append(out, def.Config)
+		}
 	}
+	return out
+}
 
-	if isEnabled(enabledChecks, "duppatterns") {
-		checks = append(checks, check.NewDuplicatedPattern())
-	}
+// Checks creates selected checks. Important thing is to do not require env variables
+// and do not create clients which will not be used because of the given checker.
+func Checks(ctx context.Context, cfgLoader *config.Loader, sel Selection) ([]check.Checker, error) {
+	return ChecksFrom(ctx, Registry(), cfgLoader, sel)
+}
 
-	if isEnabled(enabledChecks, "files") {
-		checks = append(checks, check.NewFileExist())
+// ChecksFrom creates checks selected from a given registry.
+func ChecksFrom(ctx context.Context, registry []Definition, cfgLoader *config.Loader, sel Selection) ([]check.Checker, error) {
+	defs, err := SelectFrom(registry, sel)
+	if err != nil {
+		return nil, err
 	}
 
-	if isEnabled(enabledChecks, "owners") {
-		var cfg struct {
-			OwnerChecker check.ValidOwnerConfig
-			Github       github.ClientConfig
-		}
-		if err := envconfig.Init(&cfg); err != nil {
-			return nil, errors.Wrapf(err, "while loading config for %s", "owners")
-		}
-
-		ghClient, isApp, err := github.NewClient(ctx, &cfg.Github)
+	var checks []check.Checker
+	for _, def := range defs {
+		c, err := def.New(ctx, cfgLoader)
 		if err != nil {
-			return nil, errors.Wrap(err, "while creating GitHub client")
+			return nil, err
 		}
+		checks = append(checks, c)
+	}
 
-		owners, err := check.NewValidOwner(cfg.OwnerChecker, ghClient, !isApp)
-		if err != nil {
-			return nil, errors.Wrap(err, "while enabling 'owners' checker")
-		}
+	return checks, nil
+}
 
-		if err := owners.CheckSatisfied(ctx); err != nil {
-			return nil, errors.Wrap(err, "while checking if 'owners' checker is satisfied")
-		}
+// Select returns definitions of the selected checks in the execution order.
+// Returns an error if any of the given names is not registered.
+func Select(sel Selection) ([]Definition, error) {
+	return SelectFrom(Registry(), sel)
+}
 
-		checks = append(checks, owners)
+// SelectFrom returns definitions of the checks selected from a given registry.
+func SelectFrom(registry []Definition, sel Selection) ([]Definition, error) {
+	for _, names := range [][]string{sel.Checks, sel.Experimental, sel.Enable, sel.Disable} {
+		if err := validateNames(registry, names); err != nil {
+			return nil, err
+		}
 	}
 
-	expChecks, err := loadExperimentalChecks(experimentalChecks)
-	if err != nil {
-		return nil, errors.Wrap(err, "while loading experimental checks")
+	var out []Definition
+	for _, def := range registry {
+		enabled := def.DefaultEnabled()
+		if len(sel.Checks) > 0 {
+			enabled = contains(sel.Checks, def.ID)
+		}
+		if contains(sel.Experimental, def.ID) || contains(sel.Enable, def.ID) {
+			enabled = true
+		}
+		if contains(sel.Disable, def.ID) {
+			enabled = false
+		}
+
+		if enabled {
+			out = append(out, def)
+		}
 	}
 
-	return append(checks, expChecks...), nil
+	return out, nil
 }
 
-func loadExperimentalChecks(experimentalChecks []string) ([]check.Checker, error) {
-	var checks []check.Checker
+func validateNames(registry []Definition, names []string) error {
+	ids := make([]string, 0, len(registry))
+	for _, def := range registry {
+		ids = append(ids, def.ID)
+	}
 
-	if contains(experimentalChecks, "notowned") {
-		var cfg struct {
-			NotOwnedChecker check.NotOwnedFileConfig
-		}
-		if err := envconfig.Init(&cfg); err != nil {
-			return nil, errors.Wrapf(err, "while loading config for %s", "notowned")
+	for _, name := range names {
+		if contains(ids, name) {
+			continue
 		}
 
-		checks = append(checks, check.NewNotOwnedFile(cfg.NotOwnedChecker))
+		msg := fmt.Sprintf("unknown check %q.", name)
+		if suggestion := closest(ids, name); suggestion != "" {
+			msg = fmt.Sprintf("unknown check %q, did you mean %q?", name, suggestion)
+		}
+		return fmt.Errorf("%s Available checks: %s", msg, strings.Join(ids, ", "))
 	}
+	return nil
+}
 
-	if contains(experimentalChecks, "avoid-shadowing") {
-		checks = append(checks, check.NewAvoidShadowing())
-	}
+// closest returns the most similar candidate or empty string if none of them is similar enough.
+func closest(candidates []string, name string) string {
+	const maxDistance = 3
 
-	return checks, nil
+	best, bestDistance := "", maxDistance+1
+	for _, c := range candidates {
+		if d := levenshtein(strings.ToLower(name), c); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best
 }
 
-func isEnabled(checks []string, name string) bool {
-	// if a user does not specify concrete checks then all checks are enabled
-	if len(checks) == 0 {
-		return true
+// levenshtein returns the edit distance between two strings.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
 	}
 
-	if contains(checks, name) {
-		return true
+	for i := 1; i <= len(ra); i++ {
+		curr := make([]int, len(rb)+1)
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
 	}
-	return false
+	return prev[len(rb)]
 }
 
 func contains(checks []string, name string) bool {
@@ -0,0 +1,79 @@
+package load_test
+
+import (
+	"testing"
+
+	"go.szostok.io/codeowners-validator/internal/load"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelect(t *testing.T) {
+	tests := map[string]struct {
+		selection load.Selection
+		expIDs    []string
+	}{
+		"Should select all stable checks by default": {
+			selection: load.Selection{},
+			expIDs:    []string{"syntax", "duppatterns", "files", "owners"},
+		},
+		"Should select only given checks": {
+			selection: load.Selection{Checks: []string{"avoid-shadowing", "files"}},
+			expIDs:    []string{"files", "avoid-shadowing"},
+		},
+		"Should append experimental checks": {
+			selection: load.Selection{Checks: []string{"files"}, Experimental: []string{"notowned"}},
+			expIDs:    []string{"files", "notowned"},
+		},
+		"Should enable and disable checks": {
+			selection: load.Selection{Enable: []string{"avoid-shadowing"}, Disable: []string{"owners", "files"}},
+			expIDs:    []string{"syntax", "duppatterns", "avoid-shadowing"},
+		},
+	}
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			// when
+			defs, err := load.Select(tc.selection)
+
+			// then
+			require.NoError(t, err)
+
+			var gotIDs []string
+			for _, d := range defs {
+				gotIDs = append(gotIDs, d.ID)
+			}
+			assert.Equal(t, tc.expIDs, gotIDs)
+		})
+	}
+}
+
+func TestSelectUnknownCheck(t *testing.T) {
+	tests := map[string]struct {
+		selection load.Selection
+		expErrMsg string
+	}{
+		"Should suggest the closest check": {
+			selection: load.Selection{Checks: []string{"file"}},
+			expErrMsg: `unknown check "file", did you mean "files"? Available checks: syntax, duppatterns, files, owners, notowned, avoid-shadowing, policy, cel, required-owners, codeowners-owned`,
+		},
+		"Should report unknown disabled check": {
+			selection: load.Selection{Disable: []string{"avoid-shadow"}},
+			expErrMsg: `unknown check "avoid-shadow", did you mean "avoid-shadowing"? Available checks: syntax, duppatterns, files, owners, notowned, avoid-shadowing, policy, cel, required-owners, codeowners-owned`,
+		},
+		"Should not suggest anything if there is no similar check": {
+			selection: load.Selection{Enable: []string{"disable-all"}},
+			expErrMsg: `unknown check "disable-all". Available checks: syntax, duppatterns, files, owners, notowned, avoid-shadowing, policy, cel, required-owners, codeowners-owned`,
+		},
+	}
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			// when
+			defs, err := load.Select(tc.selection)
+
+			// then
+			assert.EqualError(t, err, tc.expErrMsg)
+			assert.Nil(t, defs)
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package load
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.szostok.io/codeowners-validator/internal/check"
+	"go.szostok.io/codeowners-validator/internal/config"
+	"go.szostok.io/codeowners-validator/internal/github"
+
+	"github.com/pkg/errors"
+)
+
+var checkIDRegexp = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Stability describes the maturity level of a check.
+type Stability string
+
+const (
+	// Stable checks are enabled by default.
+	Stable Stability = "stable"
+	// Experimental checks are disabled by default and need to be enabled explicitly.
+	Experimental Stability = "experimental"
+)
+
+// Dependency describes an external dependency required by a check.
+type Dependency string
+
+const (
+	// GitBinary means that the check executes the `git` binary.
+	GitBinary Dependency = "git"
+	// GitHubAPI means that the check calls the GitHub API, so it requires network access and GitHub authorization.
+	GitHubAPI Dependency = "github-api"
+)
+
+// Definition describes a check registered in the registry.
+type Definition struct {
+	// ID is used to select the check, e.g. in the CHECKS environment variable.
+	ID string
+	// Name is a human-readable name of the check.
+	Name        string
+	Description string
+	Stability   Stability
+	// Config is a pointer to the check configuration struct, nil if the check is not configurable.
+	Config       interface{}
+	Dependencies []Dependency
+	// New creates the check. Config is loaded only when the check is enabled,
+	// so the required options and clients are not needed for disabled checks.
+	New func(ctx context.Context, cfgLoader *config.Loader) (check.Checker, error)
+}
+
+// DefaultEnabled returns true if the check is executed when a user doesn't select checks explicitly.
+func (d Definition) DefaultEnabled() bool {
+	return d.Stability == Stable
+}
+
+type (
+	ownersConfig struct {
+		OwnerChecker check.ValidOwnerConfig
+		Github       github.ClientConfig
+	}
+
+	notOwnedConfig struct {
+		NotOwnedChecker check.NotOwnedFileConfig
+	}
+
+	// checks which execute git share the trust workspace option with the notowned check,
+	// so a single NOT_OWNED_CHECKER_TRUST_WORKSPACE setting applies to all of them.
+	policyConfig struct {
+		Policy          check.PolicyConfig
+		NotOwnedChecker check.WorkspaceConfig
+	}
+
+	celConfig struct {
+		CEL             check.CELConfig
+		NotOwnedChecker check.WorkspaceConfig
+	}
+
+	requiredOwnersConfig struct {
+		RequiredOwners  check.RequiredOwnersConfig
+		NotOwnedChecker check.WorkspaceConfig
+	}
+
+	codeownersOwnedConfig struct {
+		CodeownersOwnedChecker check.CodeownersOwnedConfig
+	}
+)
+
+// Registry returns definitions of all available checks in the execution order.
+func Registry() []Definition {
+	return []Definition{
+		{
+			ID:          "syntax",
+			Name:        "Valid Syntax Checker",
+			Description: "Reports if CODEOWNERS file contain invalid syntax definition.",
+			Stability:   Stable,
+			New: func(context.Context, *config.Loader) (check.Checker, error) {
+				return check.NewValidSyntax(), nil
+			},
+		},
+		{
+			ID:          "duppatterns",
+			Name:        "Duplicated Pattern Checker",
+			Description: "Reports if CODEOWNERS file contain duplicated lines with the same file pattern.",
+			Stability:   Stable,
+			New: func(context.Context, *config.Loader) (check.Checker, error) {
+				return check.NewDuplicatedPattern(), nil
+			},
+		},
+		{
+			ID:          "files",
+			Name:        "File Exist Checker",
+			Description: "Reports if CODEOWNERS file contain lines with the file pattern that do not exist in a given repository.",
+			Stability:   Stable,
+			New: func(context.Context, *config.Loader) (check.Checker, error) {
+				return check.NewFileExist(), nil
+			},
+		},
+		{
+			ID:           "owners",
+			Name:         "Valid Owner Checker",
+			Description:  "Reports if CODEOWNERS file contain invalid owners definition.",
+			Stability:    Stable,
+			Config:       &ownersConfig{},
+			Dependencies: []Dependency{GitHubAPI},
+			New:          newValidOwner,
+		},
+		{
+			ID:           "notowned",
+			Name:         "Not Owned File Checker",
+			Description:  "Reports if a given repository contain files that do not have specified owners in CODEOWNERS file.",
+			Stability:    Experimental,
+			Config:       &notOwnedConfig{},
+			Dependencies: []Dependency{GitBinary},
+			New: func(_ context.Context, cfgLoader *config.Loader) (check.Checker, error) {
+				var cfg notOwnedConfig
+				if err := cfgLoader.Load(&cfg); err != nil {
+					return nil, errors.Wrapf(err, "while loading config for %s", "notowned")
+				}
+				return check.NewNotOwnedFile(cfg.NotOwnedChecker), nil
+			},
+		},
+		{
+			ID:          "avoid-shadowing",
+			Name:        "Avoid Shadowing Checker",
+			Description: "Reports if entries go from least specific to most specific. Otherwise, earlier entries are completely ignored.",
+			Stability:   Experimental,
+			New: func(context.Context, *config.Loader) (check.Checker, error) {
+				return check.NewAvoidShadowing(), nil
+			},
+		},
+		{
+			ID:           "policy",
+			Name:         "Policy Checker",
+			Description:  "Reports files which effective owners break the declarative policy rules.",
+			Stability:    Experimental,
+			Config:       &policyConfig{},
+			Dependencies: []Dependency{GitBinary},
+			New: func(_ context.Context, cfgLoader *config.Loader) (check.Checker, error) {
+				var cfg policyConfig
+				if err := cfgLoader.Load(&cfg); err != nil {
+					return nil, errors.Wrapf(err, "while loading config for %s", "policy")
+				}
+				cfg.Policy.Workspace = cfg.NotOwnedChecker
+				return check.NewPolicy(cfg.Policy)
+			},
+		},
+		{
+			ID:           "cel",
+			Name:         "CEL Rules Checker",
+			Description:  "Reports CODEOWNERS entries and files which break the custom rules written as CEL expressions.",
+			Stability:    Experimental,
+			Config:       &celConfig{},
+			Dependencies: []Dependency{GitBinary},
+			New: func(_ context.Context, cfgLoader *config.Loader) (check.Checker, error) {
+				var cfg celConfig
+				if err := cfgLoader.Load(&cfg); err != nil {
+					return nil, errors.Wrapf(err, "while loading config for %s", "cel")
+				}
+				cfg.CEL.Workspace = cfg.NotOwnedChecker
+				return check.NewCEL(cfg.CEL)
+			},
+		},
+		{
+			ID:           "required-owners",
+			Name:         "Required Owners Checker",
+			Description:  "Reports files which effective owners don't include the owners required for their paths, e.g. security team for workflows.",
+			Stability:    Experimental,
+			Config:       &requiredOwnersConfig{},
+			Dependencies: []Dependency{GitBinary},
+			New: func(_ context.Context, cfgLoader *config.Loader) (check.Checker, error) {
+				var cfg requiredOwnersConfig
+				if err := cfgLoader.Load(&cfg); err != nil {
+					return nil, errors.Wrapf(err, "while loading config for %s", "required-owners")
+				}
+				cfg.RequiredOwners.Workspace = cfg.NotOwnedChecker
+				return check.NewRequiredOwners(cfg.RequiredOwners)
+			},
+		},
+		{
+			ID:          "codeowners-owned",
+			Name:        "CODEOWNERS Ownership Checker",
+			Description: "Reports if the CODEOWNERS file itself is not owned or is owned only by the catch-all entry.",
+			Stability:   Experimental,
+			Config:      &codeownersOwnedConfig{},
+			New: func(_ context.Context, cfgLoader *config.Loader) (check.Checker, error) {
+				var cfg codeownersOwnedConfig
+				if err := cfgLoader.Load(&cfg); err != nil {
+					return nil, errors.Wrapf(err, "while loading config for %s", "codeowners-owned")
+				}
+				return check.NewCodeownersOwned(cfg.CodeownersOwnedChecker), nil
+			},
+		},
+	}
+}
+
+// Plugins returns definitions of checks executed as external plugins.
+func Plugins(cfgs []check.PluginConfig) []Definition {
+	out := make([]Definition, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		cfg := cfg
+		out = append(out, Definition{
+			ID:          cfg.ID,
+			Name:        cfg.Name,
+			Description: fmt.Sprintf("Executes the %q plugin.", strings.Join(cfg.Command, " ")),
+			Stability:   Stable,
+			New: func(context.Context, *config.Loader) (check.Checker, error) {
+				return check.NewPlugin(cfg)
+			},
+		})
+	}
+	return out
+}
+
+// Extend returns the built-in registry extended with given custom definitions.
+// Custom checks are executed after the built-in ones.
+func Extend(custom ...Definition) ([]Definition, error) {
+	registry := Registry()
+	for _, def := range custom {
+		switch {
+		case !checkIDRegexp.MatchString(def.ID):
+			return nil, fmt.Errorf("invalid check ID %q: only lowercase letters, digits and dashes are allowed", def.ID)
+		case def.New == nil:
+			return nil, fmt.Errorf("check %q doesn't have a constructor", def.ID)
+		}
+		for _, registered := range registry {
+			if registered.ID == def.ID {
+				return nil, fmt.Errorf("check %q is already registered", def.ID)
+			}
+		}
+		registry = append(registry, def)
+	}
+	return registry, nil
+}
+
+func newValidOwner(ctx context.Context, cfgLoader *config.Loader) (check.Checker, error) {
+	var cfg ownersConfig
+	if err := cfgLoader.Load(&cfg); err != nil {
+		return nil, errors.Wrapf(err, "while loading config for %s", "owners")
+	}
+
+	backend, checkScopes, err := github.NewBackend(ctx, &cfg.Github)
+	if err != nil {
+		return nil, errors.Wrap(err, "while creating GitHub backend")
+	}
+
+	owners, err := check.NewValidOwner(cfg.OwnerChecker, backend, checkScopes)
+	if err != nil {
+		return nil, errors.Wrap(err, "while enabling 'owners' checker")
+	}
+
+	if err := owners.CheckSatisfied(ctx); err != nil {
+		return nil, errors.Wrap(err, "while checking if 'owners' checker is satisfied")
+	}
+
+	return owners, nil
+}
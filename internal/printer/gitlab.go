@@ -0,0 +1,137 @@
+package printer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"go.szostok.io/codeowners-validator/internal/check"
+)
+
+type (
+	// gitLabIssue represents a single entry in the GitLab Code Quality report.
+	// see: https://docs.gitlab.com/ee/ci/testing/code_quality.html#implement-a-custom-tool
+	gitLabIssue struct {
+		Description string         `json:"description"`
+		CheckName   string         `json:"check_name"`
+		Fingerprint string         `json:"fingerprint"`
+		Severity    string         `json:"severity"`
+		Location    gitLabLocation `json:"location"`
+	}
+
+	gitLabLocation struct {
+		Path  string      `json:"path"`
+		Lines gitLabLines `json:"lines"`
+	}
+
+	gitLabLines struct {
+		Begin uint64 `json:"begin"`
+	}
+)
+
+// GitLabCodeQualityPrinter prints the checks results as the GitLab Code Quality JSON report.
+// The report is printed once all checks are executed.
+type GitLabCodeQualityPrinter struct {
+	reportCollector
+	codeownersPath string
+}
+
+// NewGitLabCodeQualityPrinter returns a new GitLabCodeQualityPrinter instance.
+// The codeownersPath is used as the issues location and should be relative to the repository root.
+func NewGitLabCodeQualityPrinter(codeownersPath string) *GitLabCodeQualityPrinter {
+	return &GitLabCodeQualityPrinter{
+		codeownersPath: codeownersPath,
+	}
+}
+
+func (p *GitLabCodeQualityPrinter) PrintCheckResult(checkName string, duration time.Duration, checkOut check.Output, checkErr error) {
+	p.collect(checkName, duration, checkOut, checkErr)
+}
+
+func (p *GitLabCodeQualityPrinter) PrintSummary(allCheck, failedChecks int) {
+	report := p.report(allCheck, failedChecks)
+
+	issues := []gitLabIssue{}
+	for _, res := range report.Checks {
+		occurrences := p.occurrences(res.Issues)
+		for idx, i := range res.Issues {
+			issue := p.newIssue(res.Name, i.Message, p.severity(i.Severity), p.line(i))
+			if occurrences[idx] > 0 {
+				issue.Fingerprint = p.fingerprint(res.Name, fmt.Sprintf("%s:%d", i.Message, occurrences[idx]))
+			}
+			issues = append(issues, issue)
+		}
+		if res.Err != nil {
+			issues = append(issues, p.newIssue(res.Name, fmt.Sprintf("Internal Error: %s", res.Err), "critical", 1))
+		}
+	}
+
+	enc := json.NewEncoder(writer)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(issues); err != nil {
+		fmt.Fprintf(os.Stderr, "while encoding GitLab Code Quality report: %v\n", err)
+	}
+}
+
+func (p *GitLabCodeQualityPrinter) newIssue(checkName, msg, severity string, line uint64) gitLabIssue {
+	return gitLabIssue{
+		Description: msg,
+		CheckName:   checkName,
+		Fingerprint: p.fingerprint(checkName, msg),
+		Severity:    severity,
+		Location: gitLabLocation{
+			Path:  p.codeownersPath,
+			Lines: gitLabLines{Begin: line},
+		},
+	}
+}
+
+// occurrences returns for each issue how many issues with the same message were reported
+// by the check in the lines above it. GitLab requires unique fingerprints, so it's used
+// to distinguish the same message reported for different entries without depending on their line numbers.
+func (p *GitLabCodeQualityPrinter) occurrences(issues []check.Issue) []int {
+	order := make([]int, len(issues))
+	for idx := range issues {
+		order[idx] = idx
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return p.line(issues[order[i]]) < p.line(issues[order[j]])
+	})
+
+	seen := map[string]int{}
+	out := make([]int, len(issues))
+	for _, idx := range order {
+		msg := issues[idx].Message
+		out[idx] = seen[msg]
+		seen[msg]++
+	}
+	return out
+}
+
+func (*GitLabCodeQualityPrinter) line(i check.Issue) uint64 {
+	if i.LineNo == nil {
+		return 1
+	}
+	return *i.LineNo
+}
+
+// fingerprint returns a stable identifier of the issue, so GitLab is able to detect
+// which issues were introduced or resolved between pipelines. The line number is not included,
+// so issues are not reported as new when lines above them are added or removed.
+func (p *GitLabCodeQualityPrinter) fingerprint(checkName, msg string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%s:%s", checkName, p.codeownersPath, msg)))
+	return hex.EncodeToString(sum[:])
+}
+
+func (*GitLabCodeQualityPrinter) severity(s check.SeverityType) string {
+	switch s {
+	case check.Warning:
+		return "minor"
+	default:
+		return "major"
+	}
+}
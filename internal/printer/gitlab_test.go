@@ -0,0 +1,112 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"go.szostok.io/codeowners-validator/internal/check"
+	"go.szostok.io/codeowners-validator/internal/ptr"
+
+	"github.com/sebdah/goldie/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitLabCodeQualityPrinter(t *testing.T) {
+	t.Run("Should print all reported issues", func(t *testing.T) {
+		// given
+		gitlab := NewGitLabCodeQualityPrinter(".github/CODEOWNERS")
+
+		buff := &bytes.Buffer{}
+		restore := overrideWriter(buff)
+		defer restore()
+
+		// when
+		gitlab.PrintCheckResult("Foo Checker", time.Second, check.Output{
+			Issues: []check.Issue{
+				{
+					Severity: check.Error,
+					LineNo:   ptr.Uint64Ptr(42),
+					Message:  "Simulate error in line 42",
+				},
+				{
+					Severity: check.Warning,
+					Message:  "Warning without line number",
+				},
+			},
+		}, nil)
+		gitlab.PrintCheckResult("Bar Checker", time.Second, check.Output{}, errors.New("some check internal error"))
+		gitlab.PrintSummary(2, 2)
+
+		// then
+		g := goldie.New(t, goldie.WithNameSuffix(".golden.txt"))
+		g.Assert(t, t.Name(), buff.Bytes())
+	})
+
+	t.Run("Should keep fingerprint when issue is moved to another line", func(t *testing.T) {
+		// given
+		gitlab := NewGitLabCodeQualityPrinter("CODEOWNERS")
+
+		// when
+		before := gitlab.newIssue("Foo Checker", "Simulate error", "major", 42)
+		after := gitlab.newIssue("Foo Checker", "Simulate error", "major", 43)
+
+		// then
+		assert.Equal(t, before.Fingerprint, after.Fingerprint)
+	})
+
+	t.Run("Should return unique fingerprints for the same message reported for different entries", func(t *testing.T) {
+		// given
+		gitlab := NewGitLabCodeQualityPrinter("CODEOWNERS")
+
+		buff := &bytes.Buffer{}
+		restore := overrideWriter(buff)
+		defer restore()
+
+		// when
+		gitlab.PrintCheckResult("Foo Checker", time.Second, check.Output{
+			Issues: []check.Issue{
+				{
+					Severity: check.Error,
+					LineNo:   ptr.Uint64Ptr(7),
+					Message:  "Missing owner, at least one owner is required",
+				},
+				{
+					Severity: check.Error,
+					LineNo:   ptr.Uint64Ptr(3),
+					Message:  "Missing owner, at least one owner is required",
+				},
+			},
+		}, nil)
+		gitlab.PrintSummary(1, 1)
+
+		// then
+		var got []gitLabIssue
+		require.NoError(t, json.Unmarshal(buff.Bytes(), &got))
+		require.Len(t, got, 2)
+		assert.NotEqual(t, got[0].Fingerprint, got[1].Fingerprint)
+
+		first := gitlab.newIssue("Foo Checker", "Missing owner, at least one owner is required", "major", 3)
+		assert.Equal(t, first.Fingerprint, got[1].Fingerprint)
+	})
+
+	t.Run("Should print empty list when there are no issues", func(t *testing.T) {
+		// given
+		gitlab := NewGitLabCodeQualityPrinter("CODEOWNERS")
+
+		buff := &bytes.Buffer{}
+		restore := overrideWriter(buff)
+		defer restore()
+
+		// when
+		gitlab.PrintCheckResult("Foo Checker", time.Second, check.Output{}, nil)
+		gitlab.PrintSummary(1, 0)
+
+		// then
+		g := goldie.New(t, goldie.WithNameSuffix(".golden.txt"))
+		g.Assert(t, t.Name(), buff.Bytes())
+	})
+}
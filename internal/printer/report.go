@@ -0,0 +1,92 @@
+package printer
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"go.szostok.io/codeowners-validator/internal/check"
+)
+
+// Printer prints the checks results.
+type Printer interface {
+	PrintCheckResult(checkName string, duration time.Duration, checkOut check.Output, err error)
+	PrintSummary(allCheck int, failedChecks int)
+}
+
+type (
+	// Report holds the results of all executed checks.
+	Report struct {
+		Checks  []CheckResult
+		Summary Summary
+	}
+
+	// CheckResult holds the result of a single executed check.
+	CheckResult struct {
+		Name     string
+		Duration time.Duration
+		Issues   []check.Issue
+		Err      error
+	}
+
+	// Summary holds the number of all and failed checks.
+	Summary struct {
+		AllChecks    int
+		FailedChecks int
+	}
+)
+
+// MarshalJSON encodes the check error as its message, as error values are not serialized by encoding/json.
+func (r CheckResult) MarshalJSON() ([]byte, error) {
+	type result CheckResult // prevents infinite recursion
+	out := struct {
+		result
+		Err *string
+	}{result: result(r)}
+	if r.Err != nil {
+		msg := r.Err.Error()
+		out.Err = &msg
+	}
+	return json.Marshal(out)
+}
+
+// reportCollector gathers check results reported by the runner, so printers
+// which need the whole picture can render it once all checks are done.
+type reportCollector struct {
+	m      sync.Mutex
+	checks []CheckResult
+}
+
+func (c *reportCollector) collect(checkName string, duration time.Duration, checkOut check.Output, checkErr error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.checks = append(c.checks, CheckResult{
+		Name:     checkName,
+		Duration: duration,
+		Issues:   checkOut.Issues,
+		Err:      checkErr,
+	})
+}
+
+// report returns collected results. Checks are executed in parallel,
+// so they are sorted by name to produce a deterministic output.
+func (c *reportCollector) report(allCheck, failedChecks int) Report {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	checks := make([]CheckResult, len(c.checks))
+	copy(checks, c.checks)
+	sort.SliceStable(checks, func(i, j int) bool {
+		return checks[i].Name < checks[j].Name
+	})
+
+	return Report{
+		Checks: checks,
+		Summary: Summary{
+			AllChecks:    allCheck,
+			FailedChecks: failedChecks,
+		},
+	}
+}
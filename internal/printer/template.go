@@ -0,0 +1,64 @@
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.szostok.io/codeowners-validator/internal/check"
+
+	"github.com/pkg/errors"
+)
+
+// templateFuncs are the additional functions available in user-defined templates.
+var templateFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"join":  strings.Join,
+	"json": func(in interface{}) (string, error) {
+		// errors are encoded as their messages, otherwise they are serialized as empty objects
+		if err, ok := in.(error); ok {
+			in = err.Error()
+		}
+		out, err := json.Marshal(in)
+		return string(out), err
+	},
+}
+
+// TemplatePrinter renders the checks results using a user-defined Go template.
+// The template is executed with the Report once all checks are executed.
+type TemplatePrinter struct {
+	reportCollector
+	tmpl *template.Template
+}
+
+// NewTemplatePrinter returns a new TemplatePrinter instance for a template stored under a given path.
+func NewTemplatePrinter(path string) (*TemplatePrinter, error) {
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "while reading template file")
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(raw))
+	if err != nil {
+		return nil, errors.Wrap(err, "while parsing template file")
+	}
+
+	return &TemplatePrinter{
+		tmpl: tmpl,
+	}, nil
+}
+
+func (p *TemplatePrinter) PrintCheckResult(checkName string, duration time.Duration, checkOut check.Output, checkErr error) {
+	p.collect(checkName, duration, checkOut, checkErr)
+}
+
+func (p *TemplatePrinter) PrintSummary(allCheck, failedChecks int) {
+	if err := p.tmpl.Execute(writer, p.report(allCheck, failedChecks)); err != nil {
+		fmt.Fprintf(os.Stderr, "while rendering template: %v\n", err)
+	}
+}
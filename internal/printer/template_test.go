@@ -0,0 +1,82 @@
+package printer
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.szostok.io/codeowners-validator/internal/check"
+	"go.szostok.io/codeowners-validator/internal/ptr"
+
+	"github.com/sebdah/goldie/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplatePrinter(t *testing.T) {
+	t.Run("Should render the whole report", func(t *testing.T) {
+		// given
+		tmpl, err := NewTemplatePrinter(filepath.Join("testdata", "report.tmpl"))
+		require.NoError(t, err)
+
+		buff := &bytes.Buffer{}
+		restore := overrideWriter(buff)
+		defer restore()
+
+		// when
+		tmpl.PrintCheckResult("Foo Checker", time.Second, check.Output{
+			Issues: []check.Issue{
+				{
+					Severity: check.Error,
+					LineNo:   ptr.Uint64Ptr(42),
+					Message:  "Simulate error in line 42",
+				},
+				{
+					Severity: check.Warning,
+					Message:  "Warning without line number",
+				},
+			},
+		}, nil)
+		tmpl.PrintCheckResult("Bar Checker", 2*time.Second, check.Output{}, errors.New("some check internal error"))
+		tmpl.PrintSummary(2, 2)
+
+		// then
+		g := goldie.New(t, goldie.WithNameSuffix(".golden.txt"))
+		g.Assert(t, t.Name(), buff.Bytes())
+	})
+
+	t.Run("Should encode check errors as messages in JSON", func(t *testing.T) {
+		// given
+		path := filepath.Join(t.TempDir(), "json.tmpl")
+		require.NoError(t, os.WriteFile(path, []byte(`{{ range .Checks }}{{ json . }} {{ json .Err }}{{ end }}`), 0o600))
+		tmpl, err := NewTemplatePrinter(path)
+		require.NoError(t, err)
+
+		buff := &bytes.Buffer{}
+		restore := overrideWriter(buff)
+		defer restore()
+
+		// when
+		tmpl.PrintCheckResult("Bar Checker", time.Second, check.Output{}, errors.New("some check internal error"))
+		tmpl.PrintSummary(1, 1)
+
+		// then
+		assert.Equal(t, `{"Name":"Bar Checker","Duration":1000000000,"Issues":null,"Err":"some check internal error"} "some check internal error"`, buff.String())
+	})
+
+	t.Run("Should return error on malformed template", func(t *testing.T) {
+		// given
+		path := filepath.Join(t.TempDir(), "malformed.tmpl")
+		require.NoError(t, os.WriteFile(path, []byte("{{ .Checks "), 0o600))
+
+		// when
+		tmpl, err := NewTemplatePrinter(path)
+
+		// then
+		assert.ErrorContains(t, err, "while parsing template file")
+		assert.Nil(t, tmpl)
+	})
+}
@@ -2,28 +2,48 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"go.szostok.io/version/extension"
 
 	"go.szostok.io/codeowners-validator/internal/check"
-	"go.szostok.io/codeowners-validator/internal/envconfig"
+	"go.szostok.io/codeowners-validator/internal/config"
 	"go.szostok.io/codeowners-validator/internal/load"
-	"go.szostok.io/codeowners-validator/internal/runner"
+	"go.szostok.io/codeowners-validator/internal/printer"
 	"go.szostok.io/codeowners-validator/pkg/codeowners"
+	"go.szostok.io/codeowners-validator/pkg/validator"
 )
 
 // Config holds the application configuration
 type Config struct {
-	RepositoryPath     string
-	CheckFailureLevel  check.SeverityType `envconfig:"default=warning"`
-	Checks             []string           `envconfig:"optional"`
-	ExperimentalChecks []string           `envconfig:"optional"`
+	RepositoryPath     string               `desc:"Path to your repository on your local machine. Can be also provided as an argument."`
+	CheckFailureLevel  check.SeverityType   `envconfig:"default=warning" desc:"Defines the level on which the application should treat check issues as failures. Possible values are error and warning."`
+	Checks             []string             `envconfig:"optional" desc:"The comma-separated list of checks to be executed. By default, all stable checks are executed."`
+	ExperimentalChecks []string             `envconfig:"optional" desc:"The comma-separated list of experimental checks to be executed."`
+	Enable             []string             `envconfig:"optional" desc:"The comma-separated list of checks to be executed in addition to the selected ones."`
+	Disable            []string             `envconfig:"optional" desc:"The comma-separated list of checks that should not be executed."`
+	OutputFormat       string               `envconfig:"default=tty" desc:"Defines the format in which the checks results are printed. Possible values are tty and gitlab-code-quality."`
+	FormatTemplate     string               `envconfig:"optional" sensitive:"true" desc:"Path to the Go template file used to render the checks results. Takes precedence over the output format."`
+	ConfigFile         string               `envconfig:"optional" desc:"Path to the configuration file. Defaults to the .codeowners-validator.yaml file from the repository root."`
+	Plugins            []check.PluginConfig `envconfig:"optional" sensitive:"true" desc:"The JSON list of external executables executed as checks, e.g. [{\"id\": \"my-rules\", \"command\": [\"./rules.py\"], \"timeout\": \"30s\"}]."`
+}
+
+// selection returns checks selected by a user.
+func (c Config) selection() load.Selection {
+	return load.Selection{
+		Checks:       c.Checks,
+		Experimental: c.ExperimentalChecks,
+		Enable:       c.Enable,
+		Disable:      c.Disable,
+	}
 }
 
 func main() {
@@ -44,6 +64,87 @@ func exitOnError(err error) {
 	}
 }
 
+// loadConfig loads the application configuration. The configuration file is discovered
+// in the repository root, so the repository path is resolved first.
+func loadConfig(flags *pflag.FlagSet) (*config.Loader, Config, error) {
+	flagSrc, envSrc := config.NewFlagSource(flags), config.NewEnvSource()
+
+	// the configuration file is located first, as it may set the required options
+	var locate fileLocationConfig
+	if err := config.NewLoader(flagSrc, envSrc).Load(&locate); err != nil {
+		return nil, Config{}, err
+	}
+
+	path := locate.ConfigFile
+	if path == "" && locate.RepositoryPath != "" {
+		discovered, err := config.DiscoverFile(locate.RepositoryPath)
+		if err != nil {
+			return nil, Config{}, errors.Wrap(err, "while discovering config file")
+		}
+		path = discovered
+	}
+
+	cfgLoader := config.NewLoader(flagSrc, envSrc)
+	if path != "" {
+		fileSrc, err := config.NewFileSource(path, locate.RepositoryPath)
+		if err != nil {
+			return nil, Config{}, err
+		}
+		if err := fileSrc.Validate(append([]interface{}{&Config{}}, load.Configs()...)...); err != nil {
+			return nil, Config{}, err
+		}
+		cfgLoader = config.NewLoader(flagSrc, envSrc, fileSrc)
+	}
+
+	var cfg Config
+	if err := cfgLoader.Load(&cfg); err != nil {
+		return nil, Config{}, err
+	}
+
+	return cfgLoader, cfg, nil
+}
+
+// fileLocationConfig holds the Config options used to locate the configuration file.
+// Both are optional, as the repository path may be set only in the configuration file.
+type fileLocationConfig struct {
+	RepositoryPath string `envconfig:"optional"`
+	ConfigFile     string `envconfig:"optional"`
+}
+
+// setRepositoryPathArg allows to provide the repository path as a positional argument.
+func setRepositoryPathArg(flags *pflag.FlagSet, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	if flags.Changed("repository-path") {
+		return errors.New("repository path cannot be provided both as an argument and the --repository-path flag")
+	}
+	return flags.Set("repository-path", args[0])
+}
+
+func newPrinter(format, tmplPath, repoPath string) (printer.Printer, error) {
+	if tmplPath != "" {
+		return printer.NewTemplatePrinter(tmplPath)
+	}
+
+	switch format {
+	case "tty":
+		return &printer.TTYPrinter{}, nil
+	case "gitlab-code-quality":
+		codeownersPath, err := codeowners.DetectFile(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		relPath, err := filepath.Rel(repoPath, codeownersPath)
+		if err != nil {
+			return nil, err
+		}
+		return printer.NewGitLabCodeQualityPrinter(filepath.ToSlash(relPath)), nil
+	default:
+		return nil, fmt.Errorf("not supported output format %q, allowed values: tty, gitlab-code-quality", format)
+	}
+}
+
 // WithStopContext returns a copy of parent with a new Done channel. The returned
 // context's Done channel is closed on of SIGINT or SIGTERM signals.
 func WithStopContext(parent context.Context) (context.Context, context.CancelFunc) {
@@ -65,44 +166,71 @@ func WithStopContext(parent context.Context) (context.Context, context.CancelFun
 // NewRoot returns a root cobra.Command for the whole Agent utility.
 func NewRoot() *cobra.Command {
 	rootCmd := &cobra.Command{
-		Use:          "codeowners-validator",
-		Short:        "Ensures the correctness of your CODEOWNERS file.",
+		Use:   "codeowners-validator [REPOSITORY_PATH]",
+		Short: "Ensures the correctness of your CODEOWNERS file.",
+		Long: "Ensures the correctness of your CODEOWNERS file.\n\n" +
+			"Each option can be set with a flag, an environment variable or the configuration file.\n" +
+			"Flags take precedence over environment variables, which take precedence over\n" +
+			"the configuration file, which takes precedence over defaults.",
+		Args:         cobra.MaximumNArgs(1),
 		SilenceUsage: true,
 		Run: func(cmd *cobra.Command, args []string) {
-			var cfg Config
-			err := envconfig.Init(&cfg)
+			err := setRepositoryPathArg(cmd.Flags(), args)
 			exitOnError(err)
 
-			log := logrus.New()
+			cfgLoader, cfg, err := loadConfig(cmd.Flags())
+			exitOnError(err)
 
 			// init checks
-			checks, err := load.Checks(cmd.Context(), cfg.Checks, cfg.ExperimentalChecks)
+			registry, err := load.Extend(load.Plugins(cfg.Plugins)...)
+			exitOnError(err)
+
+			checks, err := load.ChecksFrom(cmd.Context(), registry, cfgLoader, cfg.selection())
 			exitOnError(err)
 
 			// init codeowners entries
 			codeownersEntries, err := codeowners.NewFromPath(cfg.RepositoryPath)
 			exitOnError(err)
 
-			// run check runner
+			// run checks
 			absRepoPath, err := filepath.Abs(cfg.RepositoryPath)
 			exitOnError(err)
 
-			checkRunner := runner.NewCheckRunner(log, codeownersEntries, absRepoPath, cfg.CheckFailureLevel, checks...)
-			checkRunner.Run(cmd.Context())
+			p, err := newPrinter(cfg.OutputFormat, cfg.FormatTemplate, absRepoPath)
+			exitOnError(err)
+
+			v := validator.New(checks, validator.WithObserver(func(res validator.CheckResult) {
+				p.PrintCheckResult(res.Name, res.Duration, check.Output{Issues: res.Issues}, res.Err)
+			}))
+			report, err := v.ValidateEntries(cmd.Context(), codeownersEntries, validator.NewDirRepository(absRepoPath))
+			p.PrintSummary(len(report.Checks), report.FailedChecks())
 
-			if cmd.Context().Err() != nil {
-				log.Error("Application was interrupted by operating system")
+			if err != nil {
+				logrus.Error("Application was interrupted by operating system")
 				os.Exit(2)
 			}
-			if checkRunner.ShouldExitWithCheckFailure() {
+			if report.Failed(cfg.CheckFailureLevel) {
 				os.Exit(3)
 			}
 		},
 	}
 
+	registerConfigFlags(rootCmd)
+
 	rootCmd.AddCommand(
+		NewChecks(),
+		NewConfigCmd(),
+		NewSnapshotCmd(),
 		extension.NewVersionCobraCmd(),
 	)
 
 	return rootCmd
 }
+
+// registerConfigFlags registers flags for the application and all checks options.
+func registerConfigFlags(cmd *cobra.Command) {
+	config.RegisterFlags(cmd.Flags(), &Config{})
+	for _, cfg := range load.Configs() {
+		config.RegisterFlags(cmd.Flags(), cfg)
+	}
+}
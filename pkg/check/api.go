@@ -0,0 +1,42 @@
+// Package check provides the API to implement custom CODEOWNERS checks.
+// Custom checks are executed the same way as the built-in ones, see the validator package.
+package check
+
+import (
+	"go.szostok.io/codeowners-validator/internal/check"
+	"go.szostok.io/codeowners-validator/pkg/codeowners"
+)
+
+type (
+	// Checker allows to execute check in a generic way.
+	Checker = check.Checker
+	// Input holds data available for checks.
+	Input = check.Input
+	// Output holds issues reported by a check.
+	Output = check.Output
+	// OutputBuilder collects issues. It's safe for concurrent use.
+	OutputBuilder = check.OutputBuilder
+	// Issue describes a single problem found by a check.
+	Issue = check.Issue
+	// SeverityType describes how serious an issue is.
+	SeverityType = check.SeverityType
+	// ReportIssueOpt customizes a reported issue.
+	ReportIssueOpt = check.ReportIssueOpt
+)
+
+const (
+	// Error is the default severity of reported issues.
+	Error = check.Error
+	// Warning is the severity of issues which can be treated as non-blocking, see the CHECK_FAILURE_LEVEL option.
+	Warning = check.Warning
+)
+
+// WithSeverity sets the severity of a reported issue. Defaults to Error.
+func WithSeverity(s SeverityType) ReportIssueOpt {
+	return check.WithSeverity(s)
+}
+
+// WithEntry binds a reported issue to the CODEOWNERS line of a given entry.
+func WithEntry(e codeowners.Entry) ReportIssueOpt {
+	return check.WithEntry(e)
+}
@@ -0,0 +1,115 @@
+package codeowners
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var errUnsupportedPattern = errors.New("unsupported pattern")
+
+// Matcher resolves the effective owners of repository files. The same as on GitHub,
+// the last entry matching a given path takes the precedence.
+// Needs to be initialized via NewMatcher func.
+type Matcher struct {
+	entries  []Entry
+	patterns []*regexp.Regexp
+}
+
+// NewMatcher returns a new Matcher instance for given CODEOWNERS entries.
+// Entries with patterns that cannot be matched, e.g. negation patterns which are not supported by GitHub, are skipped.
+func NewMatcher(entries []Entry) *Matcher {
+	m := &Matcher{}
+	for _, e := range entries {
+		re, err := patternToRegexp(e.Pattern)
+		if err != nil {
+			continue
+		}
+		m.entries = append(m.entries, e)
+		m.patterns = append(m.patterns, re)
+	}
+	return m
+}
+
+// Match returns the last entry matching a given file path. The path is slash-separated
+// and relative to the repository root, e.g. docs/README.md.
+func (m *Matcher) Match(filePath string) (Entry, bool) {
+	filePath = strings.TrimPrefix(filePath, "/")
+	for i := len(m.patterns) - 1; i >= 0; i-- {
+		if m.patterns[i].MatchString(filePath) {
+			return m.entries[i], true
+		}
+	}
+	return Entry{}, false
+}
+
+// patternToRegexp converts CODEOWNERS pattern into a regexp matching file paths.
+// see: https://docs.github.com/en/repositories/managing-your-repositorys-settings-and-features/customizing-your-repository/about-code-owners#codeowners-syntax
+func patternToRegexp(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" || strings.HasPrefix(pattern, "!") {
+		return nil, errUnsupportedPattern
+	}
+
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	// patterns with a slash at the beginning or in the middle are relative to the repository root
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var re strings.Builder
+	re.WriteString("^")
+	if !anchored {
+		re.WriteString("(?:.*/)?")
+	}
+
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch {
+		case segment == "**" && last:
+			re.WriteString(".*")
+		case segment == "**":
+			re.WriteString("(?:.*/)?")
+		default:
+			re.WriteString(segmentToRegexp(segment))
+			if !last {
+				re.WriteString("/")
+			}
+		}
+	}
+
+	switch last := segments[len(segments)-1]; {
+	case last == "**":
+	case last == "*" && len(segments) > 1 && !dirOnly:
+		// `docs/*` matches files directly in the docs directory, but not in its subdirectories
+	case dirOnly:
+		re.WriteString("/.*")
+	default:
+		// a pattern matching a directory matches all files in it
+		re.WriteString("(?:/.*)?")
+	}
+	re.WriteString("$")
+
+	return regexp.Compile(re.String())
+}
+
+func segmentToRegexp(segment string) string {
+	var re strings.Builder
+	for i := 0; i < len(segment); i++ {
+		switch c := segment[i]; c {
+		case '*':
+			re.WriteString("[^/]*")
+		case '?':
+			re.WriteString("[^/]")
+		case '\\':
+			if i+1 < len(segment) {
+				i++
+				re.WriteString(regexp.QuoteMeta(string(segment[i])))
+			}
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return re.String()
+}
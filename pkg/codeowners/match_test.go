@@ -0,0 +1,128 @@
+package codeowners_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.szostok.io/codeowners-validator/pkg/codeowners"
+)
+
+func TestMatcher(t *testing.T) {
+	tests := map[string]struct {
+		pattern   string
+		matches   []string
+		noMatches []string
+	}{
+		"Should match all files": {
+			pattern: "*",
+			matches: []string{"README.md", "docs/a/b.md"},
+		},
+		"Should match extension anywhere": {
+			pattern:   "*.js",
+			matches:   []string{"app.js", "src/web/app.js"},
+			noMatches: []string{"app.jsx", "src/app.go"},
+		},
+		"Should match directory anywhere": {
+			pattern:   "apps/",
+			matches:   []string{"apps/main.go", "src/apps/web/main.go"},
+			noMatches: []string{"apps", "src/apps.go"},
+		},
+		"Should match directory relative to the root": {
+			pattern:   "/build/logs/",
+			matches:   []string{"build/logs/out.log", "build/logs/2020/out.log"},
+			noMatches: []string{"src/build/logs/out.log"},
+		},
+		"Should match only direct children for trailing asterisk": {
+			pattern:   "docs/*",
+			matches:   []string{"docs/getting-started.md"},
+			noMatches: []string{"docs/build-app/troubleshooting.md", "src/docs/a.md"},
+		},
+		"Should match all directories for trailing asterisk with slash": {
+			pattern:   "services/*/",
+			matches:   []string{"services/auth/main.go", "services/auth/cmd/main.go"},
+			noMatches: []string{"services/README.md"},
+		},
+		"Should match file or directory without trailing slash": {
+			pattern: "/script",
+			matches: []string{"script", "script/run.sh"},
+		},
+		"Should match directory anywhere with double asterisk": {
+			pattern:   "**/logs",
+			matches:   []string{"logs/a.log", "build/logs/a.log", "deeply/nested/logs/a/b.log"},
+			noMatches: []string{"logs.txt"},
+		},
+		"Should match everything inside directory": {
+			pattern:   "abc/**",
+			matches:   []string{"abc/a.go", "abc/x/y/z.go"},
+			noMatches: []string{"abc", "x/abc/a.go"},
+		},
+		"Should match zero or more directories in the middle": {
+			pattern:   "a/**/b",
+			matches:   []string{"a/b", "a/x/b", "a/x/y/b/c.go"},
+			noMatches: []string{"x/a/b"},
+		},
+		"Should match single character": {
+			pattern:   "/file?.txt",
+			matches:   []string{"file1.txt"},
+			noMatches: []string{"file10.txt", "dir/file1.txt"},
+		},
+		"Should not match negation pattern": {
+			pattern:   "!/codeowners-validator",
+			noMatches: []string{"codeowners-validator", "!/codeowners-validator"},
+		},
+	}
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			m := codeowners.NewMatcher([]codeowners.Entry{{LineNo: 1, Pattern: tc.pattern, Owners: []string{"@pico"}}})
+
+			for _, p := range tc.matches {
+				// when
+				_, found := m.Match(p)
+
+				// then
+				assert.True(t, found, "expected %q to match %q", tc.pattern, p)
+			}
+			for _, p := range tc.noMatches {
+				// when
+				_, found := m.Match(p)
+
+				// then
+				assert.False(t, found, "expected %q to not match %q", tc.pattern, p)
+			}
+		})
+	}
+}
+
+func TestMatcherLastMatchWins(t *testing.T) {
+	// given
+	entries := codeowners.ParseCodeowners(strings.NewReader(`
+*                 @org/everyone
+/docs/            @org/docs
+/docs/internal/
+*.go              @org/go
+`))
+	m := codeowners.NewMatcher(entries)
+
+	tests := map[string]struct {
+		expLineNo uint64
+		expOwners []string
+	}{
+		"README.md":              {expLineNo: 2, expOwners: []string{"@org/everyone"}},
+		"docs/index.md":          {expLineNo: 3, expOwners: []string{"@org/docs"}},
+		"docs/internal/notes.md": {expLineNo: 4, expOwners: []string{}},
+		"docs/main.go":           {expLineNo: 5, expOwners: []string{"@org/go"}},
+	}
+	for path, tc := range tests {
+		t.Run(path, func(t *testing.T) {
+			// when
+			entry, found := m.Match(path)
+
+			// then
+			assert.True(t, found)
+			assert.Equal(t, tc.expLineNo, entry.LineNo)
+			assert.ElementsMatch(t, tc.expOwners, entry.Owners)
+		})
+	}
+}
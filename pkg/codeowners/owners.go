@@ -2,9 +2,10 @@ package codeowners
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
-	"os"
+	iofs "io/fs"
 	"path"
 	"strings"
 
@@ -20,6 +21,8 @@ type Entry struct {
 	LineNo  uint64
 	Pattern string
 	Owners  []string
+	// Section holds the first line of the last comment block defined above the entry, e.g. "Frontend team".
+	Section string
 }
 
 func (e Entry) String() string {
@@ -37,28 +40,45 @@ func NewFromPath(repoPath string) ([]Entry, error) {
 }
 
 // openCodeownersFile finds a CODEOWNERS file and returns content.
-// see: https://help.github.com/articles/about-code-owners/#codeowners-file-location
 func openCodeownersFile(dir string) (io.Reader, error) {
-	var detectedFiles []string
-	for _, p := range []string{".", "docs", ".github"} {
-		pth := path.Join(dir, p)
-		exists, err := afero.DirExists(fs, pth)
-		if err != nil {
-			return nil, err
-		}
+	f, err := DetectFile(dir)
+	if err != nil {
+		return nil, err
+	}
 
-		if !exists {
-			continue
-		}
+	return fs.Open(f)
+}
+
+// locations holds directories in which the CODEOWNERS file can be defined.
+var locations = []string{".", "docs", ".github"}
 
-		f := path.Join(pth, "CODEOWNERS")
-		_, err = fs.Stat(f)
+// DetectFile returns the path to the CODEOWNERS file defined in a given repository.
+func DetectFile(dir string) (string, error) {
+	f, err := detectFile(afero.NewIOFS(afero.NewBasePathFs(fs, dir)), " "+dir)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(dir, f), nil
+}
+
+// DetectFileFS returns the slash-separated path to the CODEOWNERS file relative to the root of a given repository file system.
+func DetectFileFS(fsys iofs.FS) (string, error) {
+	return detectFile(fsys, "")
+}
+
+// detectFile returns the slash-separated path to the CODEOWNERS file relative to the repository root.
+// The repo suffix is appended to the error messages to describe the repository.
+func detectFile(fsys iofs.FS, repo string) (string, error) {
+	var detectedFiles []string
+	for _, p := range locations {
+		f := path.Join(p, "CODEOWNERS")
+		info, err := iofs.Stat(fsys, f)
 		switch {
-		case err == nil:
-		case os.IsNotExist(err):
+		case err == nil && !info.IsDir():
+		case err == nil, errors.Is(err, iofs.ErrNotExist):
 			continue
 		default:
-			return nil, err
+			return "", err
 		}
 
 		detectedFiles = append(detectedFiles, f)
@@ -66,13 +86,12 @@ func openCodeownersFile(dir string) (io.Reader, error) {
 
 	switch l := len(detectedFiles); l {
 	case 0:
-		return nil, fmt.Errorf("No CODEOWNERS found in the root, docs/, or .github/ directory of the repository %s", dir)
+		return "", fmt.Errorf("No CODEOWNERS found in the root, docs/, or .github/ directory of the repository%s", repo)
 	case 1:
-		return fs.Open(detectedFiles[0])
+		return detectedFiles[0], nil
 	default:
-		return nil, fmt.Errorf("Multiple CODEOWNERS files found in the %s locations of the repository %s",
-			english.OxfordWordSeries(replacePrefix(detectedFiles, dir, "./"), "and"),
-			dir)
+		return "", fmt.Errorf("Multiple CODEOWNERS files found in the %s locations of the repository%s",
+			english.OxfordWordSeries(replacePrefix(detectedFiles, "", "./"), "and"), repo)
 	}
 }
 
@@ -84,7 +103,11 @@ func replacePrefix(in []string, prefix, s string) []string {
 }
 
 func ParseCodeowners(r io.Reader) []Entry {
-	var e []Entry
+	var (
+		e         []Entry
+		section   string
+		inComment bool
+	)
 	s := bufio.NewScanner(r)
 	no := uint64(0)
 	for s.Scan() {
@@ -92,12 +115,18 @@ func ParseCodeowners(r io.Reader) []Entry {
 		fields := strings.Fields(s.Text())
 
 		if len(fields) == 0 { // empty
+			inComment = false
 			continue
 		}
 
 		if strings.HasPrefix(fields[0], "#") { // comment
+			if !inComment {
+				section = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(s.Text()), "#"))
+			}
+			inComment = true
 			continue
 		}
+		inComment = false
 
 		n := len(fields)
 		for idx, x := range fields {
@@ -111,6 +140,7 @@ func ParseCodeowners(r io.Reader) []Entry {
 			Pattern: fields[0],
 			Owners:  fields[1:n],
 			LineNo:  no,
+			Section: section,
 		})
 	}
 
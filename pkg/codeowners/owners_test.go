@@ -3,7 +3,9 @@ package codeowners_test
 import (
 	"fmt"
 	"path"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
@@ -30,26 +32,31 @@ func TestParseCodeownersSuccess(t *testing.T) {
 			LineNo:  3,
 			Pattern: "*",
 			Owners:  []string{"@everyone"},
+			Section: "Sample codeowner file",
 		},
 		{
 			LineNo:  5,
 			Pattern: "src/**",
 			Owners:  []string{"@org/hakuna-matata", "@pico-bello"},
+			Section: "Sample codeowner file",
 		},
 		{
 			LineNo:  6,
 			Pattern: "pkg/github.com/**",
 			Owners:  []string{"@myk"},
+			Section: "Sample codeowner file",
 		},
 		{
 			LineNo:  7,
 			Pattern: "tests/**",
 			Owners:  []string{"@ghost"},
+			Section: "Sample codeowner file",
 		},
 		{
 			LineNo:  8,
 			Pattern: "internal/**",
 			Owners:  []string{"@ghost"},
+			Section: "Sample codeowner file",
 		},
 	}
 
@@ -109,6 +116,59 @@ func TestFindCodeownersFileSuccess(t *testing.T) {
 	}
 }
 
+func TestDetectFileSuccess(t *testing.T) {
+	// given
+	tFS := afero.NewMemMapFs()
+	revert := codeowners.SetFS(tFS)
+	defer revert()
+
+	givenRepoPath := "/workspace/go/repo-name"
+	_, err := tFS.Create(path.Join(givenRepoPath, ".github", "CODEOWNERS"))
+	require.NoError(t, err)
+
+	// when
+	gotPath, err := codeowners.DetectFile(givenRepoPath)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "/workspace/go/repo-name/.github/CODEOWNERS", gotPath)
+}
+
+func TestDetectFileFS(t *testing.T) {
+	tests := map[string]struct {
+		givenFS   fstest.MapFS
+		expPath   string
+		expErrMsg string
+	}{
+		"Should detect file in .github/": {
+			givenFS: fstest.MapFS{".github/CODEOWNERS": &fstest.MapFile{}, "CODEOWNERS/README.md": &fstest.MapFile{}},
+			expPath: ".github/CODEOWNERS",
+		},
+		"Should report that no CODEOWNERS file was found": {
+			givenFS:   fstest.MapFS{"README.md": &fstest.MapFile{}},
+			expErrMsg: "No CODEOWNERS found in the root, docs/, or .github/ directory of the repository",
+		},
+		"Should report that CODEOWNERS file was found on root and docs/": {
+			givenFS:   fstest.MapFS{"CODEOWNERS": &fstest.MapFile{}, "docs/CODEOWNERS": &fstest.MapFile{}},
+			expErrMsg: "Multiple CODEOWNERS files found in the ./CODEOWNERS and ./docs/CODEOWNERS locations of the repository",
+		},
+	}
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			// when
+			gotPath, err := codeowners.DetectFileFS(tc.givenFS)
+
+			// then
+			if tc.expErrMsg != "" {
+				assert.EqualError(t, err, tc.expErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expPath, gotPath)
+		})
+	}
+}
+
 func TestFindCodeownersFileFailure(t *testing.T) {
 	// given
 	tFS := afero.NewMemMapFs()
@@ -174,3 +234,26 @@ func TestMultipleCodeownersFileFailure(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCodeownersSections(t *testing.T) {
+	// given
+	content := `
+# Frontend team
+# Reviews all web changes
+/web/   @org/frontend
+
+/web/docs/ @org/docs # inline comments are ignored
+
+# Infrastructure
+/infra/ @org/platform
+`
+
+	// when
+	entries := codeowners.ParseCodeowners(strings.NewReader(content))
+
+	// then
+	require.Len(t, entries, 3)
+	assert.Equal(t, "Frontend team", entries[0].Section)
+	assert.Equal(t, "Frontend team", entries[1].Section)
+	assert.Equal(t, "Infrastructure", entries[2].Section)
+}
@@ -0,0 +1,77 @@
+package validator
+
+import (
+	"context"
+
+	"go.szostok.io/codeowners-validator/internal/check"
+	"go.szostok.io/codeowners-validator/internal/config"
+	"go.szostok.io/codeowners-validator/internal/load"
+)
+
+// Selection holds the IDs of checks to create, e.g. owners or notowned.
+type Selection struct {
+	// Checks replaces the default set of checks. By default, all stable built-in checks
+	// and all custom checks are created.
+	Checks []string
+	// Enable is appended to the selected checks.
+	Enable []string
+	// Disable is removed from the selected checks.
+	Disable []string
+}
+
+// CheckDefinition describes a custom check implemented with the check package.
+type CheckDefinition struct {
+	// ID is used to select the check. Only lowercase letters, digits and dashes are allowed.
+	ID          string
+	Description string
+	// Experimental checks are created only if they are selected explicitly.
+	Experimental bool
+	// New creates the check. It's called only if the check is selected.
+	New func(ctx context.Context) (Checker, error)
+}
+
+// SelectChecks creates the selected checks. The built-in checks are the same as available in the CLI,
+// and the custom ones are executed after them. Options of built-in checks are keyed by the names
+// of the CLI environment variables, e.g.
+//
+//	validator.SelectChecks(ctx, validator.Selection{Checks: []string{"owners"}}, map[string]string{
+//		"OWNER_CHECKER_REPOSITORY": "org/repo",
+//		"GITHUB_ACCESS_TOKEN":      token,
+//	})
+//
+// Options of checks which are not selected are ignored.
+func SelectChecks(ctx context.Context, sel Selection, options map[string]string, custom ...CheckDefinition) ([]Checker, error) {
+	defs := make([]load.Definition, 0, len(custom))
+	for _, c := range custom {
+		defs = append(defs, toDefinition(c))
+	}
+
+	registry, err := load.Extend(defs...)
+	if err != nil {
+		return nil, err
+	}
+
+	cfgLoader := config.NewLoader(config.MapSource(options))
+	return load.ChecksFrom(ctx, registry, cfgLoader, load.Selection{
+		Checks:  sel.Checks,
+		Enable:  sel.Enable,
+		Disable: sel.Disable,
+	})
+}
+
+func toDefinition(c CheckDefinition) load.Definition {
+	def := load.Definition{
+		ID:          c.ID,
+		Description: c.Description,
+		Stability:   load.Stable,
+	}
+	if c.Experimental {
+		def.Stability = load.Experimental
+	}
+	if c.New != nil {
+		def.New = func(ctx context.Context, _ *config.Loader) (check.Checker, error) {
+			return c.New(ctx)
+		}
+	}
+	return def
+}
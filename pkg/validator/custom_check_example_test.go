@@ -0,0 +1,71 @@
+package validator_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing/fstest"
+
+	"go.szostok.io/codeowners-validator/pkg/check"
+	"go.szostok.io/codeowners-validator/pkg/validator"
+)
+
+// ServiceOwners reports service directories without a team owner whose slug ends in -owners.
+type ServiceOwners struct{}
+
+func (ServiceOwners) Name() string {
+	return "Service Owners Checker"
+}
+
+func (ServiceOwners) Check(ctx context.Context, in check.Input) (check.Output, error) {
+	var bldr check.OutputBuilder
+	for _, entry := range in.CodeownersEntries {
+		if ctx.Err() != nil {
+			return check.Output{}, ctx.Err()
+		}
+		if !strings.HasPrefix(entry.Pattern, "/services/") {
+			continue
+		}
+
+		hasTeam := false
+		for _, o := range entry.Owners {
+			hasTeam = hasTeam || (strings.Contains(o, "/") && strings.HasSuffix(o, "-owners"))
+		}
+		if !hasTeam {
+			bldr.ReportIssue(fmt.Sprintf("%s must be owned by a *-owners team", entry.Pattern), check.WithEntry(entry))
+		}
+	}
+	return bldr.Output(), nil
+}
+
+func ExampleSelectChecks_custom() {
+	ctx := context.Background()
+
+	checks, err := validator.SelectChecks(ctx, validator.Selection{Checks: []string{"syntax", "service-owners"}}, nil,
+		validator.CheckDefinition{
+			ID:          "service-owners",
+			Description: "Reports service directories without a *-owners team.",
+			New: func(context.Context) (check.Checker, error) {
+				return ServiceOwners{}, nil
+			},
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	codeowners := strings.NewReader("/services/billing/ @org/billing-owners\n/services/auth/ @org/auth-devs\n")
+	report, err := validator.New(checks).Validate(ctx, codeowners, validator.Repository{FS: fstest.MapFS{}})
+	if err != nil {
+		panic(err)
+	}
+
+	for _, c := range report.Checks {
+		for _, i := range c.Issues {
+			fmt.Printf("%s: line %d: %s\n", c.Name, *i.LineNo, i.Message)
+		}
+	}
+
+	// Output:
+	// Service Owners Checker: line 2: /services/auth/ must be owned by a *-owners team
+}
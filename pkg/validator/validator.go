@@ -0,0 +1,161 @@
+// Package validator validates CODEOWNERS files. It's the engine behind the codeowners-validator CLI,
+// and it can be embedded in other Go programs, such as services or bots.
+package validator
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"go.szostok.io/codeowners-validator/pkg/check"
+	"go.szostok.io/codeowners-validator/pkg/codeowners"
+)
+
+type (
+	// Checker validates CODEOWNERS entries.
+	Checker = check.Checker
+	// Issue describes a single problem found by a checker. Its severity is one of the check package
+	// severities, e.g. check.Error.
+	Issue = check.Issue
+)
+
+// Repository gives checks access to the validated repository.
+type Repository struct {
+	// FS gives access to the repository files, e.g. to check if a pattern matches any file.
+	FS fs.FS
+	// Dir is the path to the repository on the local disk. It's required only by checks
+	// which execute the git binary, e.g. notowned.
+	Dir string
+}
+
+// NewDirRepository returns a repository stored in a given local directory.
+func NewDirRepository(dir string) Repository {
+	return Repository{FS: os.DirFS(dir), Dir: dir}
+}
+
+type (
+	// Report holds the results of all executed checks in the order in which checks were given.
+	Report struct {
+		Checks []CheckResult
+	}
+
+	// CheckResult holds the result of a single executed check.
+	CheckResult struct {
+		Name     string
+		Duration time.Duration
+		Issues   []Issue
+		// Err is set if the check could not be executed.
+		Err error
+	}
+)
+
+// Passed returns true if the check was executed and didn't report any issues.
+func (r CheckResult) Passed() bool {
+	return r.Err == nil && len(r.Issues) == 0
+}
+
+// FailedChecks returns the number of checks which didn't pass.
+func (r Report) FailedChecks() int {
+	cnt := 0
+	for _, c := range r.Checks {
+		if !c.Passed() {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// Failed returns true if any issue has the given severity or a more serious one.
+// Checks which could not be executed are treated as errors.
+func (r Report) Failed(treatedAsFailure check.SeverityType) bool {
+	for _, c := range r.Checks {
+		if c.Err != nil && check.Error <= treatedAsFailure {
+			return true
+		}
+		for _, i := range c.Issues {
+			if i.Severity <= treatedAsFailure {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Option configures the Validator.
+type Option func(*Validator)
+
+// WithObserver registers a function which is called as soon as a given check is done,
+// e.g. to print results in a streaming fashion. Calls are never concurrent.
+func WithObserver(fn func(CheckResult)) Option {
+	return func(v *Validator) {
+		v.observer = fn
+	}
+}
+
+// Validator executes checks against CODEOWNERS files.
+// Needs to be initialized via New func.
+type Validator struct {
+	checks   []Checker
+	observer func(CheckResult)
+}
+
+// New returns a new Validator instance which executes given checks.
+// See SelectChecks to create the built-in and custom checks.
+func New(checks []Checker, opts ...Option) *Validator {
+	v := &Validator{checks: checks}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Validate parses a given CODEOWNERS content and executes all checks against it.
+func (v *Validator) Validate(ctx context.Context, codeownersFile io.Reader, repo Repository) (Report, error) {
+	return v.ValidateEntries(ctx, codeowners.ParseCodeowners(codeownersFile), repo)
+}
+
+// ValidateEntries executes all checks in parallel against already parsed CODEOWNERS entries.
+// Failures of single checks are reported in the returned Report. An error is returned only
+// if the given context was canceled.
+func (v *Validator) ValidateEntries(ctx context.Context, entries []codeowners.Entry, repo Repository) (Report, error) {
+	var (
+		wg      sync.WaitGroup
+		m       sync.Mutex
+		results = make([]CheckResult, len(v.checks))
+	)
+
+	in := check.Input{
+		RepoDir:           repo.Dir,
+		RepoFS:            repo.FS,
+		CodeownersEntries: entries,
+	}
+
+	wg.Add(len(v.checks))
+	for idx, c := range v.checks {
+		go func(idx int, c Checker) {
+			defer wg.Done()
+
+			startTime := time.Now()
+			out, err := c.Check(ctx, in)
+			res := CheckResult{
+				Name:     c.Name(),
+				Duration: time.Since(startTime),
+				Issues:   out.Issues,
+				Err:      err,
+			}
+
+			m.Lock()
+			defer m.Unlock()
+			results[idx] = res
+			if v.observer != nil {
+				v.observer(res)
+			}
+		}(idx, c)
+	}
+	wg.Wait()
+
+	return Report{Checks: results}, ctx.Err()
+}
@@ -0,0 +1,36 @@
+package validator_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing/fstest"
+
+	"go.szostok.io/codeowners-validator/pkg/validator"
+)
+
+func ExampleValidator_Validate() {
+	ctx := context.Background()
+
+	checks, err := validator.SelectChecks(ctx, validator.Selection{Checks: []string{"syntax", "files"}}, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	repo := validator.Repository{FS: fstest.MapFS{"main.go": &fstest.MapFile{}}}
+	codeowners := strings.NewReader("*.go @org/go-team\n/docs/ @org/docs-team\n")
+
+	report, err := validator.New(checks).Validate(ctx, codeowners, repo)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, c := range report.Checks {
+		for _, i := range c.Issues {
+			fmt.Printf("%s: [%s] line %d: %s\n", c.Name, i.Severity, *i.LineNo, i.Message)
+		}
+	}
+
+	// Output:
+	// File Exist Checker: [Error] line 2: "/docs/" does not match any files in repository
+}
@@ -0,0 +1,195 @@
+package validator_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"go.szostok.io/codeowners-validator/pkg/check"
+	"go.szostok.io/codeowners-validator/pkg/validator"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	// given
+	ctx := context.Background()
+	checks, err := validator.SelectChecks(ctx, validator.Selection{Checks: []string{"syntax", "duppatterns", "files"}}, nil)
+	require.NoError(t, err)
+
+	repo := validator.Repository{FS: fstest.MapFS{
+		"pkg/app/main.go": &fstest.MapFile{},
+		"docs/README.md":  &fstest.MapFile{},
+	}}
+	codeowners := strings.NewReader(`
+*.go   @org/go
+/docs/ @org/docs
+/docs/ @org/docs
+/tmp/  @org/ops
+`)
+
+	// when
+	report, err := validator.New(checks).Validate(ctx, codeowners, repo)
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, report.Checks, 3)
+
+	assert.Equal(t, "Valid Syntax Checker", report.Checks[0].Name)
+	assert.True(t, report.Checks[0].Passed())
+
+	assert.Equal(t, "Duplicated Pattern Checker", report.Checks[1].Name)
+	require.Len(t, report.Checks[1].Issues, 1)
+	assert.Equal(t, check.Error, report.Checks[1].Issues[0].Severity)
+
+	assert.Equal(t, "File Exist Checker", report.Checks[2].Name)
+	require.Len(t, report.Checks[2].Issues, 1)
+	assert.Equal(t, `"/tmp/" does not match any files in repository`, report.Checks[2].Issues[0].Message)
+
+	assert.Equal(t, 2, report.FailedChecks())
+	assert.True(t, report.Failed(check.Error))
+	assert.True(t, report.Failed(check.Warning))
+}
+
+func TestValidateObserver(t *testing.T) {
+	// given
+	ctx := context.Background()
+	checks, err := validator.SelectChecks(ctx, validator.Selection{Checks: []string{"syntax", "duppatterns"}}, nil)
+	require.NoError(t, err)
+
+	var observed []string
+	v := validator.New(checks, validator.WithObserver(func(res validator.CheckResult) {
+		observed = append(observed, res.Name)
+	}))
+
+	// when
+	report, err := v.Validate(ctx, strings.NewReader("* @org/team"), validator.Repository{FS: fstest.MapFS{}})
+
+	// then
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Valid Syntax Checker", "Duplicated Pattern Checker"}, observed)
+	assert.Zero(t, report.FailedChecks())
+	assert.False(t, report.Failed(check.Warning))
+}
+
+func TestSelectChecksFailures(t *testing.T) {
+	t.Run("Should report unknown check", func(t *testing.T) {
+		// when
+		checks, err := validator.SelectChecks(context.Background(), validator.Selection{Checks: []string{"file"}}, nil)
+
+		// then
+		assert.EqualError(t, err, `unknown check "file", did you mean "files"? Available checks: syntax, duppatterns, files, owners, notowned, avoid-shadowing, policy, cel, required-owners, codeowners-owned`)
+		assert.Nil(t, checks)
+	})
+
+	t.Run("Should report missing required option", func(t *testing.T) {
+		// when
+		checks, err := validator.SelectChecks(context.Background(), validator.Selection{Checks: []string{"owners"}}, map[string]string{})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing required configuration OwnerChecker.Repository")
+		assert.Nil(t, checks)
+	})
+}
+
+func TestSelectCustomChecks(t *testing.T) {
+	newCustom := func(id string, experimental bool) validator.CheckDefinition {
+		return validator.CheckDefinition{
+			ID:           id,
+			Experimental: experimental,
+			New: func(context.Context) (validator.Checker, error) {
+				return namedChecker(id), nil
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		selection validator.Selection
+		expNames  []string
+	}{
+		"Should append stable custom checks to the default ones": {
+			selection: validator.Selection{Disable: []string{"owners", "files"}},
+			expNames:  []string{"Valid Syntax Checker", "Duplicated Pattern Checker", "house-rules"},
+		},
+		"Should create experimental custom checks only if enabled": {
+			selection: validator.Selection{Checks: []string{"syntax"}, Enable: []string{"naming"}},
+			expNames:  []string{"Valid Syntax Checker", "naming"},
+		},
+	}
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			// when
+			checks, err := validator.SelectChecks(context.Background(), tc.selection, nil, newCustom("house-rules", false), newCustom("naming", true))
+
+			// then
+			require.NoError(t, err)
+
+			var gotNames []string
+			for _, c := range checks {
+				gotNames = append(gotNames, c.Name())
+			}
+			assert.Equal(t, tc.expNames, gotNames)
+		})
+	}
+}
+
+func TestSelectCustomChecksFailures(t *testing.T) {
+	newChecker := func(context.Context) (validator.Checker, error) { return namedChecker("custom"), nil }
+
+	tests := map[string]struct {
+		custom    validator.CheckDefinition
+		expErrMsg string
+	}{
+		"Should reject already registered ID": {
+			custom:    validator.CheckDefinition{ID: "files", New: newChecker},
+			expErrMsg: `check "files" is already registered`,
+		},
+		"Should reject invalid ID": {
+			custom:    validator.CheckDefinition{ID: "House Rules", New: newChecker},
+			expErrMsg: `invalid check ID "House Rules": only lowercase letters, digits and dashes are allowed`,
+		},
+		"Should reject definition without constructor": {
+			custom:    validator.CheckDefinition{ID: "house-rules"},
+			expErrMsg: `check "house-rules" doesn't have a constructor`,
+		},
+	}
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			// when
+			checks, err := validator.SelectChecks(context.Background(), validator.Selection{}, nil, tc.custom)
+
+			// then
+			assert.EqualError(t, err, tc.expErrMsg)
+			assert.Nil(t, checks)
+		})
+	}
+}
+
+type namedChecker string
+
+func (c namedChecker) Name() string { return string(c) }
+
+func (namedChecker) Check(context.Context, check.Input) (check.Output, error) {
+	return check.Output{}, nil
+}
+
+func TestValidateCanceledContext(t *testing.T) {
+	// given
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	checks, err := validator.SelectChecks(ctx, validator.Selection{Checks: []string{"files"}}, nil)
+	require.NoError(t, err)
+
+	// when
+	report, err := validator.New(checks).Validate(ctx, strings.NewReader("* @org/team"), validator.Repository{FS: fstest.MapFS{}})
+
+	// then
+	assert.True(t, errors.Is(err, context.Canceled))
+	require.Len(t, report.Checks, 1)
+	assert.True(t, errors.Is(report.Checks[0].Err, context.Canceled))
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"go.szostok.io/codeowners-validator/internal/config"
+	"go.szostok.io/codeowners-validator/internal/github"
+)
+
+// snapshotConfig holds options of the snapshot export command.
+type snapshotConfig struct {
+	Github github.ClientConfig
+}
+
+// NewSnapshotCmd returns a cobra.Command for managing organization snapshots used to validate owners offline.
+func NewSnapshotCmd() *cobra.Command {
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Manages organization snapshots used to validate owners without calling GitHub.",
+	}
+
+	var (
+		output string
+		repos  []string
+	)
+	exportCmd := &cobra.Command{
+		Use:   "export ORGANIZATION",
+		Short: "Exports organization members, teams and collaborators to a JSON snapshot.",
+		Long: "Exports organization members, teams with their parent teams and repository permissions,\n" +
+			"repository collaborators with their permissions, and outside collaborators to a JSON snapshot.\n\n" +
+			"Use the snapshot with GITHUB_BACKEND=snapshot and GITHUB_SNAPSHOT_PATH to validate owners offline.\n" +
+			"Listing outside collaborators requires the organization owner permission.\n\n" +
+			"Collaborators of all organization repositories are exported by default, which takes at least one API call\n" +
+			"per repository. Use the --repository flag to export only the collaborators of the validated repositories.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var cfg snapshotConfig
+			if err := config.NewLoader(config.NewFlagSource(cmd.Flags()), config.NewEnvSource()).Load(&cfg); err != nil {
+				return err
+			}
+			cfg.Github.Backend = github.RESTBackend
+
+			ghClient, _, err := github.NewClient(cmd.Context(), &cfg.Github)
+			if err != nil {
+				return errors.Wrap(err, "while creating GitHub client")
+			}
+
+			snapshot, err := github.ExportSnapshot(cmd.Context(), ghClient, github.APIHost(cfg.Github.BaseURL), args[0], repos)
+			if err != nil {
+				return errors.Wrap(err, "while exporting snapshot")
+			}
+
+			return writeSnapshot(cmd.OutOrStdout(), output, snapshot)
+		},
+	}
+	config.RegisterFlags(exportCmd.Flags(), &snapshotConfig{})
+	for _, name := range []string{"github-backend", "github-snapshot-path", "github-cache-dir", "github-cache-ttl"} {
+		// the snapshot is always exported from the live API
+		_ = exportCmd.Flags().MarkHidden(name)
+	}
+	exportCmd.Flags().StringVarP(&output, "output", "o", "", "Path to the snapshot file. Defaults to the standard output.")
+	exportCmd.Flags().StringSliceVarP(&repos, "repository", "r", nil, "Name of the repository which collaborators are exported, can be repeated. Defaults to all organization repositories.")
+
+	snapshotCmd.AddCommand(exportCmd)
+
+	return snapshotCmd
+}
+
+func writeSnapshot(stdout io.Writer, path string, snapshot *github.Snapshot) error {
+	raw, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "while marshaling snapshot")
+	}
+	raw = append(raw, '\n')
+
+	if path == "" {
+		_, err := stdout.Write(raw)
+		return err
+	}
+	return errors.Wrap(os.WriteFile(path, raw, 0o600), "while writing snapshot")
+}
@@ -77,16 +77,14 @@ func TestCheckSuccess(t *testing.T) {
 				{
 					name: "avoid-shadowing",
 					envs: Envs{
-						"CHECKS":              "disable-all",
-						"EXPERIMENTAL_CHECKS": "avoid-shadowing",
+						"CHECKS": "avoid-shadowing",
 					},
 				},
 				{
 					name: "notowned",
 					envs: Envs{
-						"PATH":                os.Getenv("PATH"), // need to be set to find the `git` binary
-						"CHECKS":              "disable-all",
-						"EXPERIMENTAL_CHECKS": "notowned",
+						"PATH":   os.Getenv("PATH"), // need to be set to find the `git` binary
+						"CHECKS": "notowned",
 					},
 					skipOS: "windows",
 				},
@@ -218,16 +216,14 @@ func TestCheckFailures(t *testing.T) {
 		{
 			name: "avoid-shadowing",
 			envs: Envs{
-				"CHECKS":              "disable-all",
-				"EXPERIMENTAL_CHECKS": "avoid-shadowing",
+				"CHECKS": "avoid-shadowing",
 			},
 		},
 		{
 			name: "notowned",
 			envs: Envs{
 				"PATH":                            os.Getenv("PATH"), // need to be set to find the `git` binary
-				"CHECKS":                          "disable-all",
-				"EXPERIMENTAL_CHECKS":             "notowned",
+				"CHECKS":                          "notowned",
 				"NOT_OWNED_CHECKER_SKIP_PATTERNS": "*",
 			},
 		},
@@ -235,8 +231,7 @@ func TestCheckFailures(t *testing.T) {
 			name: "notowned_sub_dirs",
 			envs: Envs{
 				"PATH":                             os.Getenv("PATH"), // need to be set to find the `git` binary
-				"CHECKS":                           "disable-all",
-				"EXPERIMENTAL_CHECKS":              "notowned",
+				"CHECKS":                           "notowned",
 				"NOT_OWNED_CHECKER_SKIP_PATTERNS":  "*",
 				"NOT_OWNED_CHECKER_SUBDIRECTORIES": "notowned/dir",
 			},